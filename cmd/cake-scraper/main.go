@@ -2,27 +2,61 @@ package main
 
 import (
 	"cake-scraper/pkg/scraper"
+	"cake-scraper/pkg/tag"
 	"cake-scraper/pkg/util"
+	"context"
 	"encoding/json"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 )
 
 var jobJsonPath = filepath.Join(util.ProjectRoot, "out/jobs.json")
 
+var (
+	incremental = flag.Bool("incremental", false, "stop paginating once known jobs are reached")
+	freshness   = flag.Duration("freshness", 24*time.Hour, "in incremental mode, skip jobs and companies fetched within this window")
+	batchSize   = flag.Int("batch-size", scraper.DefaultBatchSize, "number of scraped jobs saved per transaction")
+	maxErrors   = flag.Int("max-errors", scraper.DefaultMaxErrors, "number of errors a run tolerates before it is aborted, or negative to never abort")
+)
+
 func main() {
-	const maxPage = 15
+	flag.Parse()
+	// Load the tag dictionary up front so a broken file fails at startup.
+	tag.LoadDictionary()
+	const (
+		maxPage = 15
+		timeout = time.Hour
+	)
 	professions := []scraper.Profession{
 		scraper.BackendDeveloper,
 		scraper.DataEngineer,
 		scraper.FrontendDeveloper,
 	}
-	sc := scraper.NewScraper(maxPage, professions...)
-	if err := sc.Update(); err != nil {
-		panic(err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sc := scraper.NewScraper(scraper.NewCakeSource(maxPage, professions...))
+	sc.MaxErrors = *maxErrors
+	sc.Incremental = *incremental
+	sc.Freshness = *freshness
+	sc.BatchSize = *batchSize
+	report, err := sc.Update(ctx)
+	reportJson, _ := json.MarshalIndent(report, "", "    ")
+	log.Printf("scrape report: %s", reportJson)
+	if err != nil {
+		log.Printf("scrape stopped early: %v", err)
+	}
+	jobs, err := sc.Query(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		log.Fatal(err)
 	}
-	jobs := sc.Query(nil)
 	jobsJson, err := json.MarshalIndent(jobs, "", "    ")
 	if err != nil {
 		log.Fatal(err)
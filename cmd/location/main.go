@@ -2,11 +2,12 @@ package main
 
 import (
 	"cake-scraper/pkg/repo/locationrepo"
+	"context"
 	"fmt"
 )
 
 func main() {
-	if err := locationrepo.NewLocationRepo().Init(); err != nil {
+	if err := locationrepo.NewLocationRepo().Init(context.Background()); err != nil {
 		fmt.Println(err)
 		return
 	}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cake-scraper/pkg/database"
+	"fmt"
+	"os"
+	"time"
+)
+
+const usage = "usage: migrate up|down|status"
+
+// Applies, reverts or lists the schema migrations of the database configured
+// by the CAKE_DB_* environment variables.
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	config, err := database.LoadConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	db, err := database.Open(config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := migrator.Down()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if reverted == nil {
+			fmt.Println("no applied migrations")
+			return
+		}
+		fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		for _, s := range status {
+			appliedAt := "pending"
+			if !s.AppliedAt.IsZero() {
+				appliedAt = "applied " + s.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"cake-scraper/pkg/app"
+	"cake-scraper/pkg/tag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,6 +12,8 @@ import (
 )
 
 func main() {
+	// Load the tag dictionary up front so a broken file fails at startup.
+	tag.LoadDictionary()
 	app := app.New(fiber.New())
 
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"cake-scraper/pkg/repo/tagrepo"
+	"context"
+	"fmt"
+)
+
+// Relinks the stored tags to their canonical tags after json/tags.json
+// changes.
+func main() {
+	changed, err := tagrepo.NewTagRepo().Canonicalize(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("relinked %d tags\n", changed)
+}
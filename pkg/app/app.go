@@ -1,14 +1,24 @@
 package app
 
 import (
-	"cake-scraper/pkg/dto"
-	"cake-scraper/pkg/job"
+	"bytes"
+	"cake-scraper/pkg/repo/categoryrepo"
+	"cake-scraper/pkg/repo/companyrepo"
 	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/repo/runrepo"
+	"cake-scraper/pkg/repo/searchrepo"
+	"cake-scraper/pkg/search"
 	"cake-scraper/pkg/util"
 	"cake-scraper/view"
+	categorycomponent "cake-scraper/view/components/categories"
 	jobcomponent "cake-scraper/view/components/jobs"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v3"
@@ -16,105 +26,662 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/static"
 )
 
+// DefaultRequestTimeout is how long a request may run before its queries are
+// canceled.
+const DefaultRequestTimeout = 30 * time.Second
+
 type App struct {
 	*fiber.App
-	jobRepo jobrepo.JobRepo
+	jobRepo      jobrepo.JobRepo
+	companyRepo  companyrepo.CompanyRepo
+	searchRepo   searchrepo.SearchRepo
+	runRepo      runrepo.RunRepo
+	categoryRepo categoryrepo.CategoryRepo
+	// RequestTimeout is how long a request may run before its queries are
+	// canceled.
+	RequestTimeout time.Duration
 }
 
 func New(app *fiber.App) *App {
+	return NewWithRepos(app, jobrepo.NewJobRepo(), companyrepo.NewCompanyRepo(), searchrepo.NewSearchRepo(), runrepo.NewRunRepo(), categoryrepo.NewCategoryRepo())
+}
+
+// NewWithRepos creates an app that serves from the given repositories instead
+// of the shared database.
+func NewWithRepos(app *fiber.App, jobRepo jobrepo.JobRepo, companyRepo companyrepo.CompanyRepo, searchRepo searchrepo.SearchRepo, runRepo runrepo.RunRepo, categoryRepo categoryrepo.CategoryRepo) *App {
 	a := &App{
 		app,
-		jobrepo.NewJobRepo(),
+		jobRepo,
+		companyRepo,
+		searchRepo,
+		runRepo,
+		categoryRepo,
+		DefaultRequestTimeout,
 	}
 
+	app.Use(a.withRequestContext)
 	app.Get("/", adaptor.HTTPHandler(
 		templ.Handler(view.Index()),
 	))
 	app.Use("/assets/*", static.New("./assets"))
+	app.Get("/companies/:slug", a.CompanyPage)
 	app.Get("/components/jobs", a.JobsComponent)
+	app.Get("/components/categories", a.CategoriesComponent)
 
 	api := app.Group("/api")
 	api.Get("/jobs", a.Jobs)
+	api.Get("/jobs/:id/history", a.JobHistory)
+	api.Get("/companies", a.Companies)
+	api.Get("/companies/:slug", a.Company)
+	api.Get("/companies/:slug/jobs", a.CompanyJobs)
+	api.Get("/searches", a.Searches)
+	api.Post("/searches", a.CreateSearch)
+	api.Get("/searches/:id", a.Search)
+	api.Put("/searches/:id", a.UpdateSearch)
+	api.Delete("/searches/:id", a.DeleteSearch)
+	api.Get("/searches/:id/jobs", a.SearchJobs)
+	api.Get("/searches/:id/new", a.NewSearchJobs)
+	api.Post("/searches/:id/viewed", a.ViewSearch)
+	api.Get("/categories", a.Categories)
+	api.Get("/tags", a.Tags)
+	api.Get("/runs", a.Runs)
+	api.Get("/runs/:id", a.Run)
 
 	return a
 }
 
+// withRequestContext gives the request a context, read with c.UserContext(),
+// that is canceled once the request runs longer than RequestTimeout or the
+// server shuts down, so that its queries stop. fasthttp does not tell when a
+// client disconnects, so the timeout bounds the queries of abandoned requests.
+func (a *App) withRequestContext(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), a.RequestTimeout)
+	defer cancel()
+	c.SetUserContext(ctx)
+	return c.Next()
+}
+
 func (a *App) Jobs(c fiber.Ctx) error {
-	jobs, err := a.jobRepo.Find(nil)
+	parser := &queryParser{queries: c.Queries()}
+	conditions := parser.conditions()
+	page, perPage := parser.page(defaultPerPage)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), conditions, page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	jobs, err := paginator.Slice(paginator.Offset(), paginator.PerPage())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
-	jobsDTO := util.Map(
-		jobs,
-		func(j *job.Job) *dto.Job {
-			return parseJob(j)
-		},
-	)
 	return c.JSON(fiber.Map{
-		"jobs": jobsDTO,
+		"jobs":       util.Map(jobs, parseJob),
+		"pagination": parsePagination(c.OriginalURL(), paginator),
 	})
 }
 
-func (a *App) JobsComponent(c fiber.Ctx) error {
-	queries := c.Queries()
-	conditions := jobrepo.NewConditions()
-	if compony, ok := queries["company"]; ok {
-		conditions.Company(compony)
-	}
-	if title, ok := queries["title"]; ok {
-		conditions.Title(title)
-	}
-	if employmentTypes, ok := queries["employmentTypes"]; ok {
-		for _, employmentType := range strings.Split(employmentTypes, ",") {
-			if et := job.NewEmploymentType(employmentType); et != job.InvalidEmploymentType {
-				conditions.EmploymentType(et)
-			}
-		}
+func (a *App) JobHistory(c fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid job id",
+		})
 	}
-	if seniorities, ok := queries["seniorities"]; ok {
-		for _, seniority := range strings.Split(seniorities, ",") {
-			if s := job.NewSeniority(seniority); s != job.InvalidSeniority {
-				conditions.Seniority(s)
-			}
-		}
+	j, err := a.jobRepo.FindByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
-	if remotes, ok := queries["remotes"]; ok {
-		for _, remote := range strings.Split(remotes, ",") {
-			if r := job.NewRemote(remote); r != job.InvalidRemote {
-				conditions.Remote(r)
-			}
-		}
+	if j == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "job not found",
+		})
+	}
+	revisions, err := a.jobRepo.FindRevisions(c.UserContext(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"job":       parseJob(j),
+		"revisions": util.Map(revisions, parseRevision),
+	})
+}
+
+func (a *App) Companies(c fiber.Ctx) error {
+	parser := &queryParser{queries: c.Queries()}
+	page, perPage := parser.page(defaultPerPage)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+
+	paginator, err := a.companyRepo.FindPaginated(c.UserContext(), page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	companies, err := paginator.Slice(paginator.Offset(), paginator.PerPage())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"companies":  util.Map(companies, parseCompany),
+		"pagination": parsePagination(c.OriginalURL(), paginator),
+	})
+}
+
+func (a *App) Company(c fiber.Ctx) error {
+	co, err := a.companyRepo.FindBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if co == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "company not found",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"company": parseCompany(co),
+	})
+}
+
+// CompanyJobs lists the jobs of a company, filtered like Jobs.
+func (a *App) CompanyJobs(c fiber.Ctx) error {
+	parser := &queryParser{queries: c.Queries()}
+	conditions := parser.conditions().CompanySlug(c.Params("slug"))
+	page, perPage := parser.page(defaultPerPage)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+
+	co, err := a.companyRepo.FindBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if co == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "company not found",
+		})
+	}
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), conditions, page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	jobs, err := paginator.Slice(paginator.Offset(), paginator.PerPage())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"company":    parseCompany(co),
+		"jobs":       util.Map(jobs, parseJob),
+		"pagination": parsePagination(c.OriginalURL(), paginator),
+	})
+}
+
+func (a *App) Searches(c fiber.Ctx) error {
+	searches, err := a.searchRepo.FindAll(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"searches": util.Map(searches, parseSearch),
+	})
+}
+
+// searchBody is the body creating or updating a saved search. Conditions are
+// serialized as by jobrepo.Conditions and keep every job if left out.
+type searchBody struct {
+	Name       string              `json:"name"`
+	Conditions *jobrepo.Conditions `json:"conditions"`
+}
+
+// parseSearchBody parses the body of c into s, or returns the message of why
+// it is invalid.
+func parseSearchBody(c fiber.Ctx, s *search.Search) string {
+	var body searchBody
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		return "invalid request body: " + err.Error()
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		return "name is required"
+	}
+	s.Name = body.Name
+	s.Conditions = jobrepo.NewConditions()
+	if body.Conditions != nil {
+		s.Conditions = *body.Conditions
+	}
+	return ""
+}
+
+func (a *App) CreateSearch(c fiber.Ctx) error {
+	s := &search.Search{}
+	if msg := parseSearchBody(c, s); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	if err := a.searchRepo.Save(c.UserContext(), s); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	saved, err := a.searchRepo.FindByID(c.UserContext(), s.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"search": parseSearch(saved),
+	})
+}
+
+// findSearch returns the saved search of the id parameter. If there is none,
+// it responds with the error instead and returns nil.
+func (a *App) findSearch(c fiber.Ctx) (*search.Search, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid search id",
+		})
+	}
+	s, err := a.searchRepo.FindByID(c.UserContext(), id)
+	if err != nil {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if s == nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "search not found",
+		})
+	}
+	return s, nil
+}
+
+func (a *App) Search(c fiber.Ctx) error {
+	s, err := a.findSearch(c)
+	if s == nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"search": parseSearch(s),
+	})
+}
+
+func (a *App) UpdateSearch(c fiber.Ctx) error {
+	s, err := a.findSearch(c)
+	if s == nil {
+		return err
+	}
+	if msg := parseSearchBody(c, s); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+	if err := a.searchRepo.Save(c.UserContext(), s); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	saved, err := a.searchRepo.FindByID(c.UserContext(), s.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if saved == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "search not found",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"search": parseSearch(saved),
+	})
+}
+
+func (a *App) DeleteSearch(c fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid search id",
+		})
+	}
+	deleted, err := a.searchRepo.Delete(c.UserContext(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "search not found",
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SearchJobs lists the jobs matching a saved search.
+func (a *App) SearchJobs(c fiber.Ctx) error {
+	parser := &queryParser{queries: c.Queries()}
+	page, perPage := parser.page(defaultPerPage)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+
+	s, err := a.findSearch(c)
+	if s == nil {
+		return err
+	}
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), s.Conditions, page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	jobs, err := paginator.Slice(paginator.Offset(), paginator.PerPage())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"search":     parseSearch(s),
+		"jobs":       util.Map(jobs, parseJob),
+		"pagination": parsePagination(c.OriginalURL(), paginator),
+	})
+}
+
+// NewSearchJobs lists the jobs of a saved search first seen since it was last
+// viewed, until before the until parameter or now. The pages link to the same
+// until, so that jobs first seen meanwhile do not shift them. Passing until
+// to ViewSearch afterwards leaves those jobs new for the next check.
+func (a *App) NewSearchJobs(c fiber.Ctx) error {
+	parser := &queryParser{queries: c.Queries()}
+	page, perPage := parser.page(defaultPerPage)
+	until := parser.time("until", false)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+	if until.IsZero() {
+		// The current time at the precision the database stores.
+		until = time.Now().UTC().Truncate(time.Second)
+	}
+
+	s, err := a.findSearch(c)
+	if s == nil {
+		return err
+	}
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), s.NewJobs(until), page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
-	if tags, ok := queries["tags"]; ok {
-		conditions.Tags(tags)
+	jobs, err := paginator.Slice(paginator.Offset(), paginator.PerPage())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
-	var page, perPage int64 = 1, 10
-	if p, ok := queries["page"]; ok {
-		i, err := strconv.ParseInt(p, 10, 64)
-		if err == nil {
-			page = i
+	requestURL, err := url.Parse(c.OriginalURL())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	queries := requestURL.Query()
+	queries.Set("until", until.UTC().Format(time.RFC3339))
+	requestURL.RawQuery = queries.Encode()
+	return c.JSON(fiber.Map{
+		"search":     parseSearch(s),
+		"until":      until.UTC(),
+		"jobs":       util.Map(jobs, parseJob),
+		"pagination": parsePagination(requestURL.String(), paginator),
+	})
+}
+
+// ViewSearch records that the new jobs of a saved search were checked, at
+// the viewed_at of the body or now.
+func (a *App) ViewSearch(c fiber.Ctx) error {
+	var body struct {
+		ViewedAt *time.Time `json:"viewed_at"`
+	}
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body: " + err.Error(),
+			})
 		}
 	}
-	if pp, ok := queries["per_page"]; ok {
-		i, err := strconv.ParseInt(pp, 10, 64)
-		if err == nil {
-			perPage = i
+	viewedAt := time.Now()
+	if body.ViewedAt != nil {
+		if body.ViewedAt.After(viewedAt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "viewed_at must not be in the future",
+			})
 		}
+		viewedAt = *body.ViewedAt
+	}
+
+	s, err := a.findSearch(c)
+	if s == nil {
+		return err
+	}
+	if err := a.searchRepo.MarkViewed(c.UserContext(), s.ID, viewedAt); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	viewed, err := a.searchRepo.FindByID(c.UserContext(), s.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if viewed == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "search not found",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"search": parseSearch(viewed),
+	})
+}
+
+func (a *App) Categories(c fiber.Ctx) error {
+	categories, err := a.categoryRepo.FindTree(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"categories": util.Map(categories, parseCategory),
+	})
+}
+
+// Tags counts the tags of the jobs matching the same filters as Jobs. The
+// tags given in tags_all are left out, so that e.g. tags_all=Go lists the
+// tags co-occurring with Go.
+func (a *App) Tags(c fiber.Ctx) error {
+	parser := &queryParser{queries: c.Queries()}
+	conditions := parser.conditions()
+	limit := parser.int("limit", 50, 1, 500)
+	if len(parser.errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": parser.errors,
+		})
+	}
+
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), conditions, 1, 1)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	counts, err := a.jobRepo.CountTags(c.UserContext(), conditions, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"jobs": paginator.Total(),
+		"tags": util.Map(counts, parseTagCount),
+	})
+}
+
+func (a *App) Runs(c fiber.Ctx) error {
+	var limit int64 = 50
+	if l, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && l > 0 {
+		limit = l
+	}
+	runs, err := a.runRepo.FindLatest(c.UserContext(), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"runs": util.Map(runs, parseRun),
+	})
+}
+
+func (a *App) Run(c fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid run id",
+		})
+	}
+	r, err := a.runRepo.FindByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if r == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "run not found",
+		})
 	}
+	links, err := a.runRepo.FindJobLinks(c.UserContext(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	runDTO := parseRun(r)
+	runDTO.JobLinks = links
+	return c.JSON(fiber.Map{
+		"run": runDTO,
+	})
+}
 
-	paginatior := a.jobRepo.FindPaginated(conditions, page, perPage)
+func (a *App) JobsComponent(c fiber.Ctx) error {
+	// The component renders whatever filters are valid rather than failing
+	// the whole list over one bad input.
+	parser := &queryParser{queries: c.Queries()}
+	conditions := parser.conditions()
+	page, perPage := parser.page(10)
+
+	params := jobcomponent.ListParams{
+		Sort:         c.Query("sort"),
+		MainCategory: c.Query("main_category"),
+		SubCategory:  c.Query("sub_category"),
+	}
+
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), conditions, page, perPage)
+	if err != nil {
+		return a.jobsError(c, err, params)
+	}
+	jobs, err := paginator.Items()
+	if err != nil {
+		return a.jobsError(c, err, params)
+	}
 	return jobcomponent.
-		List(util.NewPaginator(func(offset, limit int64) []*dto.Job {
-			jobs := paginatior.Slice(offset, limit)
-			jobsDTO := util.Map(
-				jobs,
-				func(j *job.Job) *dto.Job {
-					return parseJob(j)
-				},
-			)
-			return jobsDTO
-		}, paginatior.CurrentPage(), paginatior.PerPage(), paginatior.Total())).
-		Render(c.Context(), c)
+		List(util.Map(jobs, parseJob), paginator, params).
+		Render(c.UserContext(), c)
+}
+
+// jobsError renders the jobs list failing to load with err.
+func (a *App) jobsError(c fiber.Ctx, err error, params jobcomponent.ListParams) error {
+	slog.Error("failed to load jobs", "error", err)
+	c.Status(fiber.StatusInternalServerError)
+	return jobcomponent.ListError(err.Error(), params).Render(c.UserContext(), c)
+}
+
+// CompanyPage renders the profile of a company with its open jobs.
+func (a *App) CompanyPage(c fiber.Ctx) error {
+	co, err := a.companyRepo.FindBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	if co == nil {
+		return c.Status(fiber.StatusNotFound).SendString("company not found")
+	}
+	conditions := jobrepo.NewConditions().CompanySlug(co.Slug).Active().SortBy(jobrepo.Sort{Key: jobrepo.SortFirstSeen, Desc: true})
+	paginator, err := a.jobRepo.FindPaginated(c.UserContext(), conditions, 1, maxPerPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	jobs, err := paginator.Items()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return view.Company(parseCompany(co), util.Map(jobs, parseJob), paginator.Total()).
+		Render(c.UserContext(), c)
+}
+
+func (a *App) CategoriesComponent(c fiber.Ctx) error {
+	categories, err := a.categoryRepo.FindTree(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return categorycomponent.
+		Sidebar(util.Map(categories, parseCategory)).
+		Render(c.UserContext(), c)
 }
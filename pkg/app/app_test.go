@@ -0,0 +1,583 @@
+package app
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/dto"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/categoryrepo"
+	"cake-scraper/pkg/repo/companyrepo"
+	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/repo/runrepo"
+	"cake-scraper/pkg/repo/searchrepo"
+	"cake-scraper/pkg/run"
+	"cake-scraper/pkg/search"
+	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/suite"
+)
+
+// failingJobRepo fails to find jobs, like a locked database.
+type failingJobRepo struct {
+	jobrepo.JobRepo
+}
+
+func (failingJobRepo) FindPaginated(ctx context.Context, conditions jobrepo.Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error) {
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*job.Job, error) {
+		return nil, errors.New("database is locked")
+	}, page, perPage, 1), nil
+}
+
+// blockingJobRepo finds jobs only once ctx is done, like a query that runs
+// until it is canceled, and sends the error of ctx to canceled.
+type blockingJobRepo struct {
+	jobrepo.JobRepo
+	canceled chan error
+}
+
+func (r blockingJobRepo) FindPaginated(ctx context.Context, conditions jobrepo.Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error) {
+	<-ctx.Done()
+	r.canceled <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+type AppTestSuite struct {
+	suite.Suite
+	jobRepo     jobrepo.JobRepo
+	companyRepo companyrepo.CompanyRepo
+	searchRepo  searchrepo.SearchRepo
+	runRepo     runrepo.RunRepo
+	app         *App
+}
+
+func (s *AppTestSuite) SetupTest() {
+	jobRepo := jobrepo.NewMemoryJobRepo()
+	s.jobRepo = jobRepo
+	s.companyRepo = companyrepo.NewMemoryCompanyRepo()
+	s.searchRepo = searchrepo.NewMemorySearchRepo()
+	s.runRepo = runrepo.NewMemoryRunRepo(jobRepo.RunLinks)
+	s.app = NewWithRepos(fiber.New(), s.jobRepo, s.companyRepo, s.searchRepo, s.runRepo, categoryrepo.NewMemoryCategoryRepo(s.jobRepo))
+}
+
+func (s *AppTestSuite) saveJob(link, company, title string) {
+	j := job.New()
+	j.Source = "cake"
+	j.Link = link
+	j.Company = company
+	j.Title = title
+	j.CompanySlug = companySlug(link)
+	_, err := s.jobRepo.Save(context.Background(), j, 0)
+	s.Require().NoError(err)
+}
+
+// companySlug returns the company slug of a Cake job link.
+func companySlug(link string) string {
+	slug, _, _ := strings.Cut(strings.TrimPrefix(link, "https://www.cake.me/companies/"), "/")
+	return slug
+}
+
+func (s *AppTestSuite) saveCompany(slug, name string) {
+	c := company.New(slug)
+	c.Name = name
+	c.Industry = "Software"
+	s.Require().NoError(s.companyRepo.Save(context.Background(), c))
+}
+
+func (s *AppTestSuite) TestJobs() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	s.saveJob("https://www.cake.me/companies/beta/jobs/frontend-engineer", "Beta", "Frontend Engineer")
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/jobs?company=Acme", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Jobs       []*dto.Job      `json:"jobs"`
+		Pagination *dto.Pagination `json:"pagination"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().Len(body.Jobs, 1)
+	s.Equal("Backend Engineer", body.Jobs[0].Title)
+	s.Equal(int64(1), body.Pagination.Total)
+}
+
+func (s *AppTestSuite) TestJobHistory_NotFound() {
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/jobs/1/history", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (s *AppTestSuite) TestJobsComponent() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/components/jobs", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Contains(string(body), "Backend Engineer")
+}
+
+func (s *AppTestSuite) TestJobsComponent_Error() {
+	// Given
+	a := NewWithRepos(fiber.New(), failingJobRepo{}, companyrepo.NewMemoryCompanyRepo(), searchrepo.NewMemorySearchRepo(), runrepo.NewMemoryRunRepo(nil), categoryrepo.NewMemoryCategoryRepo(failingJobRepo{}))
+
+	// When
+	resp, err := a.Test(httptest.NewRequest("GET", "/components/jobs", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusInternalServerError, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Contains(string(body), `id="jobs-list"`)
+	s.Contains(string(body), "database is locked")
+}
+
+func (s *AppTestSuite) TestCompanies() {
+	// Given
+	s.saveCompany("beta", "Beta")
+	s.saveCompany("acme", "Acme")
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/companies", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Companies  []*dto.Company  `json:"companies"`
+		Pagination *dto.Pagination `json:"pagination"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().Len(body.Companies, 2)
+	s.Equal("acme", body.Companies[0].Slug)
+	s.Equal("Software", body.Companies[0].Industry)
+	s.Equal(int64(2), body.Pagination.Total)
+}
+
+func (s *AppTestSuite) TestCompanyJobs() {
+	// Given
+	s.saveCompany("acme", "Acme")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	s.saveJob("https://www.cake.me/companies/beta/jobs/frontend-engineer", "Beta", "Frontend Engineer")
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/companies/acme/jobs", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Company *dto.Company `json:"company"`
+		Jobs    []*dto.Job   `json:"jobs"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Equal("Acme", body.Company.Name)
+	s.Require().Len(body.Jobs, 1)
+	s.Equal("Backend Engineer", body.Jobs[0].Title)
+	s.Equal("acme", body.Jobs[0].CompanySlug)
+}
+
+func (s *AppTestSuite) TestCompanyJobs_NotFound() {
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/companies/acme/jobs", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (s *AppTestSuite) TestCompanyPage() {
+	// Given
+	s.saveCompany("acme", "Acme")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/companies/acme", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Contains(string(body), "Software")
+	s.Contains(string(body), "Backend Engineer")
+}
+
+func (s *AppTestSuite) TestCategories() {
+	// Given
+	for link, categories := range map[string][2]string{
+		"https://www.cake.me/companies/acme/jobs/backend-engineer": {"Software", "Backend"},
+		"https://www.cake.me/companies/acme/jobs/data-analyst":     {"Data", ""},
+	} {
+		j := job.New()
+		j.Source = "cake"
+		j.Link = link
+		j.MainCategory, j.SubCategory = categories[0], categories[1]
+		_, err := s.jobRepo.Save(context.Background(), j, 0)
+		s.Require().NoError(err)
+	}
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/categories", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Categories []*dto.Category `json:"categories"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().Len(body.Categories, 2)
+	s.Equal("Data", body.Categories[0].Name)
+	s.Empty(body.Categories[0].Subs)
+	s.Equal("Software", body.Categories[1].Name)
+	s.Equal(1, body.Categories[1].ActiveJobs)
+	s.Require().Len(body.Categories[1].Subs, 1)
+	s.Equal("Backend", body.Categories[1].Subs[0].Name)
+}
+
+func (s *AppTestSuite) TestCategoriesComponent() {
+	// Given
+	j := job.New()
+	j.Source = "cake"
+	j.Link = "https://www.cake.me/companies/acme/jobs/backend-engineer"
+	j.MainCategory, j.SubCategory = "Software", "Backend"
+	_, err := s.jobRepo.Save(context.Background(), j, 0)
+	s.Require().NoError(err)
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/components/categories", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Contains(string(body), "Software")
+	s.Contains(string(body), "Backend")
+}
+
+func (s *AppTestSuite) TestRuns() {
+	// Given
+	startedAt := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		s.Require().NoError(s.runRepo.Save(context.Background(), run.New(startedAt.Add(time.Duration(i)*time.Hour))))
+	}
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/runs?limit=2", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Runs []*dto.Run `json:"runs"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().Len(body.Runs, 2)
+	s.Equal(int64(3), body.Runs[0].ID)
+	s.Equal(int64(2), body.Runs[1].ID)
+}
+
+func (s *AppTestSuite) TestRun() {
+	// Given
+	r := run.New(time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.runRepo.Save(context.Background(), r))
+	j := job.New()
+	j.Source = "cake"
+	j.Link = "https://www.cake.me/companies/acme/jobs/backend-engineer"
+	_, err := s.jobRepo.Save(context.Background(), j, r.ID)
+	s.Require().NoError(err)
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/runs/%d", r.ID), nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Run *dto.Run `json:"run"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Equal(r.ID, body.Run.ID)
+	s.Equal("running", body.Run.Status)
+	s.Equal([]string{j.Link}, body.Run.JobLinks)
+}
+
+func (s *AppTestSuite) TestRun_NotFound() {
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", "/api/runs/1", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (s *AppTestSuite) saveSearch(name string, conditions jobrepo.Conditions) *search.Search {
+	saved := search.New(name, conditions)
+	s.Require().NoError(s.searchRepo.Save(context.Background(), saved))
+	return saved
+}
+
+// searchJobsBody is the body listing the jobs of a saved search.
+type searchJobsBody struct {
+	Search     *dto.Search     `json:"search"`
+	Jobs       []*dto.Job      `json:"jobs"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+func (s *AppTestSuite) TestCreateSearch() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	s.saveJob("https://www.cake.me/companies/beta/jobs/frontend-engineer", "Beta", "Frontend Engineer")
+	req := httptest.NewRequest("POST", "/api/searches", strings.NewReader(`{
+		"name": " Acme backend ",
+		"conditions": {"state": "active", "company": "Acme", "sort": ["-first_seen"]}
+	}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	// When
+	resp, err := s.app.Test(req)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusCreated, resp.StatusCode)
+	var created struct {
+		Search *dto.Search `json:"search"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&created))
+	s.Equal("Acme backend", created.Search.Name)
+	s.Nil(created.Search.LastViewedAt)
+
+	resp, err = s.app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/searches/%d/jobs", created.Search.ID), nil))
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body searchJobsBody
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().Len(body.Jobs, 1)
+	s.Equal("Backend Engineer", body.Jobs[0].Title)
+}
+
+func (s *AppTestSuite) TestCreateSearch_Invalid() {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no name", `{"conditions": {"company": "Acme"}}`},
+		{"unknown condition", `{"name": "Acme", "conditions": {"companies": ["Acme"]}}`},
+		{"unknown remote", `{"name": "Remote", "conditions": {"remotes": ["Remote"]}}`},
+		{"malformed", `{"name": "Acme"`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// When
+			resp, err := s.app.Test(httptest.NewRequest("POST", "/api/searches", strings.NewReader(tt.body)))
+
+			// Then
+			s.Require().NoError(err)
+			s.Equal(fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+	searches, err := s.searchRepo.FindAll(context.Background())
+	s.Require().NoError(err)
+	s.Empty(searches)
+}
+
+func (s *AppTestSuite) TestUpdateSearch() {
+	// Given
+	saved := s.saveSearch("Acme", jobrepo.NewConditions().Company("Acme"))
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("PUT", fmt.Sprintf("/api/searches/%d", saved.ID), strings.NewReader(`{
+		"name": "Beta",
+		"conditions": {"company": "Beta"}
+	}`)))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	stored, err := s.searchRepo.FindByID(context.Background(), saved.ID)
+	s.Require().NoError(err)
+	s.Equal("Beta", stored.Name)
+	conditions, err := json.Marshal(stored.Conditions)
+	s.Require().NoError(err)
+	s.JSONEq(`{"company": "Beta"}`, string(conditions))
+}
+
+func (s *AppTestSuite) TestDeleteSearch() {
+	// Given
+	saved := s.saveSearch("Acme", jobrepo.NewConditions().Company("Acme"))
+	path := fmt.Sprintf("/api/searches/%d", saved.ID)
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("DELETE", path, nil))
+	s.Require().NoError(err)
+	respAgain, err := s.app.Test(httptest.NewRequest("DELETE", path, nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusNoContent, resp.StatusCode)
+	s.Equal(fiber.StatusNotFound, respAgain.StatusCode)
+}
+
+func (s *AppTestSuite) TestNewSearchJobs() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	s.saveJob("https://www.cake.me/companies/beta/jobs/frontend-engineer", "Beta", "Frontend Engineer")
+	saved := s.saveSearch("Acme", jobrepo.NewConditions().Company("Acme"))
+	// Jobs first seen in the second of until are left for the next check.
+	later := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	path := fmt.Sprintf("/api/searches/%d/new", saved.ID)
+
+	tests := []struct {
+		name     string
+		viewedAt time.Time
+		query    string
+		want     int
+	}{
+		{"never viewed", time.Time{}, "?until=" + later, 1},
+		{"viewed before", time.Now().Add(-time.Hour), "?until=" + later, 1},
+		{"viewed since", time.Now().Add(time.Hour), "?until=" + later, 0},
+		{"until before", time.Time{}, "?until=2024-10-01", 0},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Require().NoError(s.searchRepo.MarkViewed(context.Background(), saved.ID, tt.viewedAt))
+
+			// When
+			resp, err := s.app.Test(httptest.NewRequest("GET", path+tt.query, nil))
+
+			// Then
+			s.Require().NoError(err)
+			s.Equal(fiber.StatusOK, resp.StatusCode)
+			var body searchJobsBody
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+			s.Len(body.Jobs, tt.want)
+		})
+	}
+}
+
+func (s *AppTestSuite) TestNewSearchJobs_SavedFirstSeen() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	later := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	hourAgo, inAnHour := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		conditions jobrepo.Conditions
+		viewedAt   time.Time
+		want       int
+	}{
+		{"within", jobrepo.NewConditions().FirstSeen(hourAgo, inAnHour), hourAgo.Add(-time.Hour), 1},
+		{"saved to before", jobrepo.NewConditions().FirstSeen(time.Time{}, hourAgo), time.Time{}, 0},
+		{"saved from after", jobrepo.NewConditions().FirstSeen(inAnHour, time.Time{}), hourAgo, 0},
+		{"viewed since saved from", jobrepo.NewConditions().FirstSeen(hourAgo, time.Time{}), inAnHour, 0},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			saved := s.saveSearch(tt.name, tt.conditions)
+			s.Require().NoError(s.searchRepo.MarkViewed(context.Background(), saved.ID, tt.viewedAt))
+
+			// When
+			resp, err := s.app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/searches/%d/new?until=%s", saved.ID, later), nil))
+
+			// Then
+			s.Require().NoError(err)
+			s.Equal(fiber.StatusOK, resp.StatusCode)
+			var body searchJobsBody
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+			s.Len(body.Jobs, tt.want)
+		})
+	}
+}
+
+func (s *AppTestSuite) TestNewSearchJobs_Pagination() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/frontend-engineer", "Acme", "Frontend Engineer")
+	saved := s.saveSearch("Acme", jobrepo.NewConditions().Company("Acme"))
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/searches/%d/new?per_page=1&until=2099-01-01", saved.ID), nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body searchJobsBody
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().NotNil(body.Pagination.Next)
+	s.Contains(*body.Pagination.Next, "until=2099-01-01T00%3A00%3A00Z")
+}
+
+func (s *AppTestSuite) TestViewSearch() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-engineer", "Acme", "Backend Engineer")
+	saved := s.saveSearch("Acme", jobrepo.NewConditions().Company("Acme"))
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("POST", fmt.Sprintf("/api/searches/%d/viewed", saved.ID), nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusOK, resp.StatusCode)
+	var body struct {
+		Search *dto.Search `json:"search"`
+	}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&body))
+	s.Require().NotNil(body.Search.LastViewedAt)
+	s.WithinDuration(time.Now(), *body.Search.LastViewedAt, time.Minute)
+}
+
+func (s *AppTestSuite) TestViewSearch_Future() {
+	// Given
+	saved := s.saveSearch("Acme", jobrepo.NewConditions())
+	viewedAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	// When
+	resp, err := s.app.Test(httptest.NewRequest("POST", fmt.Sprintf("/api/searches/%d/viewed", saved.ID), strings.NewReader(`{"viewed_at": "`+viewedAt+`"}`)))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (s *AppTestSuite) TestRequestTimeout() {
+	// Given
+	repo := blockingJobRepo{JobRepo: s.jobRepo, canceled: make(chan error, 1)}
+	app := NewWithRepos(fiber.New(), repo, s.companyRepo, s.searchRepo, s.runRepo, categoryrepo.NewMemoryCategoryRepo(s.jobRepo))
+	app.RequestTimeout = 10 * time.Millisecond
+
+	// When
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/jobs", nil))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(fiber.StatusInternalServerError, resp.StatusCode)
+	s.ErrorIs(<-repo.canceled, context.DeadlineExceeded)
+}
+
+func TestAppTestSuite(t *testing.T) {
+	suite.Run(t, new(AppTestSuite))
+}
@@ -1,13 +1,25 @@
 package app
 
 import (
+	"cake-scraper/pkg/category"
+	"cake-scraper/pkg/company"
 	"cake-scraper/pkg/dto"
 	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/run"
+	"cake-scraper/pkg/search"
+	"cake-scraper/pkg/util"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 func parseJob(j *job.Job) *dto.Job {
-	return &dto.Job{
+	d := &dto.Job{
+		ID:               j.ID,
+		Source:           j.Source,
 		Company:          j.Company,
+		CompanySlug:      j.CompanySlug,
 		Title:            j.Title,
 		Link:             j.Link,
 		MainCategory:     j.MainCategory,
@@ -17,11 +29,162 @@ func parseJob(j *job.Job) *dto.Job {
 		Location:         j.Location,
 		NumberToHire:     j.NumberToHire,
 		Experience:       j.Experience,
+		ExperienceYears:  j.ExperienceYears,
 		Salary:           j.Salary,
 		Remote:           j.Remote.String(),
 		InterviewProcess: j.InterviewProcess,
 		JobDescription:   j.JobDescription,
 		Requirements:     j.Requirements,
 		Tags:             j.Tags,
+		FirstSeenAt:      j.FirstSeenAt,
+		LastSeenAt:       j.LastSeenAt,
+		UpdatedAt:        j.UpdatedAt,
+	}
+	if s := j.SalaryRange; s != nil {
+		if s.Min != 0 {
+			d.SalaryMin = &s.Min
+		}
+		if s.Max != 0 {
+			d.SalaryMax = &s.Max
+		}
+		annual := s.Annual()
+		d.SalaryCurrency = s.Currency
+		d.SalaryPeriod = s.Period.String()
+		d.SalaryAnnual = &annual
+	}
+	if j.Closed() {
+		d.ClosedAt = &j.ClosedAt
+	}
+	if !j.ChangedAt.IsZero() {
+		d.ChangedAt = &j.ChangedAt
+	}
+	if j.Snippet != "" {
+		d.Snippet = highlight(j.Snippet)
+	}
+	return d
+}
+
+// highlight escapes a search snippet as HTML and marks its matches.
+func highlight(snippet string) string {
+	return strings.NewReplacer(
+		job.HighlightStart, "<mark>",
+		job.HighlightEnd, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
+
+func parseRevision(r *job.Revision) *dto.Revision {
+	d := &dto.Revision{
+		ID:        r.ID,
+		ChangedAt: r.ChangedAt,
+		Changes: util.Map(r.Changes, func(c job.Change) dto.Change {
+			return dto.Change(c)
+		}),
+	}
+	if r.RunID != 0 {
+		d.RunID = &r.RunID
+	}
+	return d
+}
+
+func parseRun(r *run.Run) *dto.Run {
+	d := &dto.Run{
+		ID:             r.ID,
+		StartedAt:      r.StartedAt,
+		Status:         string(r.Status),
+		Sources:        r.Sources,
+		Professions:    r.Professions,
+		PagesRequested: r.PagesRequested,
+		PagesVisited:   r.PagesVisited,
+		JobsNew:        r.JobsNew,
+		JobsUpdated:    r.JobsUpdated,
+		JobsUnchanged:  r.JobsUnchanged,
+		JobsSkipped:    r.JobsSkipped,
+		JobsClosed:     r.JobsClosed,
+		Errors:         r.Errors,
+		ErrorMessages:  r.ErrorMessages,
+		Error:          r.Error,
+	}
+	if !r.FinishedAt.IsZero() {
+		d.FinishedAt = &r.FinishedAt
+	}
+	return d
+}
+
+func parseCompany(c *company.Company) *dto.Company {
+	d := &dto.Company{
+		ID:       c.ID,
+		Slug:     c.Slug,
+		Name:     c.Name,
+		Industry: c.Industry,
+		Size:     c.Size,
+		Location: c.Location,
+		Website:  c.Website,
+		Benefits: c.Benefits,
+	}
+	if c.FoundedYear != 0 {
+		d.FoundedYear = &c.FoundedYear
+	}
+	if c.Fetched() {
+		d.FetchedAt = &c.FetchedAt
+	}
+	return d
+}
+
+func parseSearch(s *search.Search) *dto.Search {
+	d := &dto.Search{
+		ID:         s.ID,
+		Name:       s.Name,
+		Conditions: s.Conditions,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+	if !s.LastViewedAt.IsZero() {
+		d.LastViewedAt = &s.LastViewedAt
+	}
+	return d
+}
+
+// parsePagination describes the current page of paginator, linking to the
+// neighbouring pages of requestURL.
+func parsePagination(requestURL string, paginator util.Pages) *dto.Pagination {
+	pagination := &dto.Pagination{
+		Total:      paginator.Total(),
+		Page:       paginator.CurrentPage(),
+		PerPage:    paginator.PerPage(),
+		TotalPages: paginator.TotalPage(),
+	}
+	pageURL := func(page int64) *string {
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			return nil
+		}
+		query := u.Query()
+		query.Set("page", strconv.FormatInt(page, 10))
+		u.RawQuery = query.Encode()
+		link := u.String()
+		return &link
+	}
+	if paginator.HasNext() {
+		pagination.Next = pageURL(paginator.CurrentPage() + 1)
+	}
+	if paginator.HasPrev() {
+		// Past the last page, the previous page is the last one.
+		pagination.Prev = pageURL(max(min(paginator.CurrentPage()-1, paginator.TotalPage()), 1))
+	}
+	return pagination
+}
+
+func parseCategory(c *category.Category) *dto.Category {
+	return &dto.Category{
+		Name:       c.Name,
+		ActiveJobs: c.ActiveJobs,
+		Subs:       util.Map(c.Subs, parseCategory),
+	}
+}
+
+func parseTagCount(t *job.TagCount) *dto.TagCount {
+	return &dto.TagCount{
+		Tag:  t.Tag,
+		Jobs: t.Jobs,
 	}
 }
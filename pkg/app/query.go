@@ -0,0 +1,210 @@
+package app
+
+import (
+	"cake-scraper/pkg/dto"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/repo/jobrepo"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// queryParser parses query parameters, collecting an error for each invalid
+// one instead of stopping at the first.
+type queryParser struct {
+	queries map[string]string
+	errors  []dto.ParamError
+}
+
+func (p *queryParser) fail(param, format string, args ...any) {
+	p.errors = append(p.errors, dto.ParamError{
+		Parameter: param,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// list returns the comma separated values of param, or nil if it is empty.
+func (p *queryParser) list(param string) []string {
+	if p.queries[param] == "" {
+		return nil
+	}
+	return strings.Split(p.queries[param], ",")
+}
+
+// int parses an integer between lo and hi, or of at least lo if hi is 0.
+func (p *queryParser) int(param string, def, lo, hi int64) int64 {
+	v, ok := p.queries[param]
+	if !ok || v == "" {
+		return def
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || i < lo || (hi != 0 && i > hi) {
+		if hi == 0 {
+			p.fail(param, "must be an integer of at least %d", lo)
+		} else {
+			p.fail(param, "must be an integer between %d and %d", lo, hi)
+		}
+		return def
+	}
+	return i
+}
+
+func (p *queryParser) float(param string) float64 {
+	v := p.queries[param]
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		p.fail(param, "must be a non-negative number")
+		return 0
+	}
+	return f
+}
+
+// time parses a date or an RFC 3339 timestamp. A date as the upper bound of a
+// range includes the whole day.
+func (p *queryParser) time(param string, upper bool) time.Time {
+	v := p.queries[param]
+	if v == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		if upper {
+			return t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		p.fail(param, "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		return time.Time{}
+	}
+	return t
+}
+
+// page returns the page and per_page parameters.
+func (p *queryParser) page(defPerPage int64) (int64, int64) {
+	return p.int("page", 1, 1, 0), p.int("per_page", defPerPage, 1, maxPerPage)
+}
+
+// conditions builds job conditions from the filter query parameters shared by
+// the jobs API and component.
+func (p *queryParser) conditions() jobrepo.Conditions {
+	conditions := jobrepo.NewConditions()
+	if sources := p.list("sources"); sources != nil {
+		conditions = conditions.Source(sources...)
+	}
+	if q := p.queries["q"]; q != "" {
+		conditions = conditions.Search(q)
+	}
+	if company := p.queries["company"]; company != "" {
+		conditions = conditions.Company(company)
+	}
+	if slug := p.queries["company_slug"]; slug != "" {
+		conditions = conditions.CompanySlug(slug)
+	}
+	if title := p.queries["title"]; title != "" {
+		conditions = conditions.Title(title)
+	}
+	if location := p.queries["location"]; location != "" {
+		conditions = conditions.Location(location)
+	}
+	// Places are separated by semicolons, as addresses contain commas.
+	for _, place := range strings.Split(p.queries["place"], ";") {
+		if strings.TrimSpace(place) == "" {
+			continue
+		}
+		if places := location.Find(place); len(places) > 0 {
+			conditions = conditions.Places(places...)
+		} else {
+			p.fail("place", "unknown place %q", place)
+		}
+	}
+	if mainCategories := p.list("main_category"); mainCategories != nil {
+		conditions = conditions.MainCategory(mainCategories...)
+	}
+	if subCategories := p.list("sub_category"); subCategories != nil {
+		conditions = conditions.SubCategory(subCategories...)
+	}
+	for _, employmentType := range p.list("employmentTypes") {
+		if et := job.NewEmploymentType(employmentType); et != job.InvalidEmploymentType {
+			conditions = conditions.EmploymentType(et)
+		} else {
+			p.fail("employmentTypes", "unknown employment type %q", employmentType)
+		}
+	}
+	for _, seniority := range p.list("seniorities") {
+		if s := job.NewSeniority(seniority); s != job.InvalidSeniority {
+			conditions = conditions.Seniority(s)
+		} else {
+			p.fail("seniorities", "unknown seniority %q", seniority)
+		}
+	}
+	for _, remote := range p.list("remotes") {
+		if r := job.NewRemote(remote); r != job.InvalidRemote {
+			conditions = conditions.Remote(r)
+		} else {
+			p.fail("remotes", "unknown remote %q", remote)
+		}
+	}
+	if tags := p.list("tags"); tags != nil {
+		conditions = conditions.Tags(tags...)
+	}
+	if tags := p.list("tags_all"); tags != nil {
+		conditions = conditions.AllTags(tags...)
+	}
+	if tags := p.list("tags_none"); tags != nil {
+		conditions = conditions.NoTags(tags...)
+	}
+	salaryMin, salaryMax := p.float("salary_min"), p.float("salary_max")
+	if salaryMax > 0 && salaryMin > salaryMax {
+		p.fail("salary_min", "must not be above salary_max")
+	} else if salaryMin > 0 || salaryMax > 0 {
+		conditions = conditions.Salary(salaryMin, salaryMax)
+	}
+	if currencies := p.list("salary_currency"); currencies != nil {
+		conditions = conditions.SalaryCurrency(currencies...)
+	}
+	switch status := p.queries["status"]; status {
+	case "", "all":
+	case "active":
+		conditions = conditions.Active()
+	case "closed":
+		conditions = conditions.Closed()
+	default:
+		p.fail("status", "must be one of all, active or closed")
+	}
+	if _, ok := p.queries["max_experience"]; ok {
+		if years := p.int("max_experience", -1, 0, 100); years >= 0 {
+			conditions = conditions.MaxExperience(int(years))
+		}
+	}
+	for _, sort := range p.list("sort") {
+		if s, err := jobrepo.ParseSort(sort); err == nil {
+			conditions = conditions.SortBy(s)
+		} else {
+			p.fail("sort", "%s", err)
+		}
+	}
+	firstSeenFrom := p.time("first_seen_from", false)
+	if newSince := p.time("new_since", false); newSince.After(firstSeenFrom) {
+		firstSeenFrom = newSince
+	}
+	firstSeenTo := p.time("first_seen_to", true)
+	if !firstSeenFrom.IsZero() || !firstSeenTo.IsZero() {
+		conditions = conditions.FirstSeen(firstSeenFrom, firstSeenTo)
+	}
+	updatedFrom, updatedTo := p.time("updated_from", false), p.time("updated_to", true)
+	if !updatedFrom.IsZero() || !updatedTo.IsZero() {
+		conditions = conditions.Updated(updatedFrom, updatedTo)
+	}
+	return conditions
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"cake-scraper/pkg/dto"
+	"cake-scraper/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func (s *QueryTestSuite) TestConditions_InvalidParameters() {
+	// Given
+	parser := &queryParser{queries: map[string]string{
+		"remotes":         "moon",
+		"status":          "open",
+		"salary_min":      "-1",
+		"first_seen_from": "yesterday",
+		"max_experience":  "ten",
+		"page":            "0",
+	}}
+
+	// When
+	parser.conditions()
+	parser.page(defaultPerPage)
+
+	// Then
+	params := util.Map(parser.errors, func(e dto.ParamError) string { return e.Parameter })
+	s.ElementsMatch([]string{"remotes", "status", "salary_min", "first_seen_from", "max_experience", "page"}, params)
+}
+
+func (s *QueryTestSuite) TestConditions_ValidParameters() {
+	// Given
+	parser := &queryParser{queries: map[string]string{
+		"remotes":        "100% Remote Work,Partial Remote Work",
+		"status":         "active",
+		"salary_min":     "600000",
+		"first_seen_to":  "2024-01-31",
+		"updated_from":   "2024-01-01T08:00:00+08:00",
+		"max_experience": "3",
+		"page":           "2",
+		"per_page":       "50",
+	}}
+
+	// When
+	parser.conditions()
+	page, perPage := parser.page(defaultPerPage)
+
+	// Then
+	s.Empty(parser.errors)
+	s.Equal(int64(2), page)
+	s.Equal(int64(50), perPage)
+}
+
+func (s *QueryTestSuite) TestParsePagination() {
+	tests := []struct {
+		name       string
+		page       int64
+		total      int64
+		next, prev string
+	}{
+		{"first page", 1, 25, "/api/jobs?page=2&per_page=10&q=go", ""},
+		{"middle page", 2, 25, "/api/jobs?page=3&per_page=10&q=go", "/api/jobs?page=1&per_page=10&q=go"},
+		{"last page", 3, 25, "", "/api/jobs?page=2&per_page=10&q=go"},
+		{"past the last page", 9, 25, "", "/api/jobs?page=3&per_page=10&q=go"},
+		{"no results", 2, 0, "", "/api/jobs?page=1&per_page=10&q=go"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			paginator := util.NewPaginator(func(offset, limit int64) []int { return nil }, tt.page, 10, tt.total)
+
+			pagination := parsePagination("/api/jobs?q=go&page=1&per_page=10", paginator)
+
+			s.Equal(tt.page, pagination.Page)
+			s.Equal(tt.next, deref(pagination.Next))
+			s.Equal(tt.prev, deref(pagination.Prev))
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func TestQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
@@ -0,0 +1,12 @@
+package category
+
+// Category is a main category, or a sub category of one, with the number of
+// active jobs in it.
+type Category struct {
+	Name string
+	// ActiveJobs counts the open jobs in the category, including those in its
+	// sub categories.
+	ActiveJobs int
+	// Subs are the sub categories of a main category.
+	Subs []*Category
+}
@@ -0,0 +1,34 @@
+package company
+
+import "time"
+
+// Company is the profile of a company posting jobs.
+type Company struct {
+	ID int64
+	// Slug identifies the company on Cake, as in /companies/{slug}.
+	Slug     string
+	Name     string
+	Industry string
+	// Size is the headcount as given by the company, e.g. "51-200".
+	Size string
+	// FoundedYear is 0 if it is unknown.
+	FoundedYear int
+	Location    string
+	Website     string
+	Benefits    []string
+	// FetchedAt is when the profile was last scraped, or zero if it never
+	// was and only the name is known from the jobs.
+	FetchedAt time.Time
+}
+
+func New(slug string) *Company {
+	return &Company{
+		Slug:     slug,
+		Benefits: []string{},
+	}
+}
+
+// Fetched reports whether the profile was scraped.
+func (c *Company) Fetched() bool {
+	return !c.FetchedAt.IsZero()
+}
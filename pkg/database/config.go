@@ -0,0 +1,112 @@
+package database
+
+import (
+	"cake-scraper/pkg/util"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables overriding the default database configuration.
+const (
+	EnvDSN          = "CAKE_DB_DSN"
+	EnvWAL          = "CAKE_DB_WAL"
+	EnvBusyTimeout  = "CAKE_DB_BUSY_TIMEOUT"
+	EnvMaxOpenConns = "CAKE_DB_MAX_OPEN_CONNS"
+)
+
+// Config is how a database is opened.
+type Config struct {
+	// DSN is the SQLite file name or "file:" URI of the database, or a
+	// "postgres://" URL of a PostgreSQL database. Each connection to an
+	// in-memory SQLite database opens a separate one, so it needs MaxOpenConns
+	// of 1.
+	DSN string
+	// WAL enables write-ahead logging, which lets readers run alongside a
+	// writer. It only applies to SQLite.
+	WAL bool
+	// BusyTimeout is how long a connection waits for a lock before failing.
+	// It only applies to SQLite.
+	BusyTimeout time.Duration
+	// MaxOpenConns limits the connection pool, or leaves it unlimited if 0.
+	MaxOpenConns int
+}
+
+// DefaultConfig returns the configuration of cake.db in the project root, so
+// that every command uses the same database wherever it runs.
+func DefaultConfig() Config {
+	return Config{
+		DSN:         "file:" + filepath.Join(util.ProjectRoot, "cake.db"),
+		WAL:         true,
+		BusyTimeout: 5 * time.Second,
+	}
+}
+
+// LoadConfig returns the default configuration overridden by the CAKE_DB_*
+// environment variables.
+func LoadConfig() (Config, error) {
+	config := DefaultConfig()
+	if dsn := os.Getenv(EnvDSN); dsn != "" {
+		config.DSN = dsn
+	}
+	if v := os.Getenv(EnvWAL); v != "" {
+		wal, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse %s: %w", EnvWAL, err)
+		}
+		config.WAL = wal
+	}
+	if v := os.Getenv(EnvBusyTimeout); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout < 0 {
+			return Config{}, fmt.Errorf("failed to parse %s: must be a non-negative duration like 5s", EnvBusyTimeout)
+		}
+		config.BusyTimeout = timeout
+	}
+	if v := os.Getenv(EnvMaxOpenConns); v != "" {
+		conns, err := strconv.Atoi(v)
+		if err != nil || conns < 0 {
+			return Config{}, fmt.Errorf("failed to parse %s: must be a non-negative integer", EnvMaxOpenConns)
+		}
+		config.MaxOpenConns = conns
+	}
+	return config, nil
+}
+
+// Dialect returns the dialect of the configured database.
+func (c Config) Dialect() Dialect {
+	return dialectOf(c.DSN)
+}
+
+// dataSource returns the DSN with the settings every connection is opened
+// with. PostgreSQL sessions use UTC, as timestamps are stored without a time
+// zone.
+func (c Config) dataSource() string {
+	if c.Dialect() == Postgres {
+		u, err := url.Parse(c.DSN)
+		if err != nil {
+			return c.DSN
+		}
+		query := u.Query()
+		if query.Get("timezone") == "" {
+			query.Set("timezone", "UTC")
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", c.BusyTimeout.Milliseconds()))
+	if c.WAL {
+		pragmas.Add("_pragma", "journal_mode(WAL)")
+	}
+	separator := "?"
+	if strings.Contains(c.DSN, "?") {
+		separator = "&"
+	}
+	return c.DSN + separator + pragmas.Encode()
+}
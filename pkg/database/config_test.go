@@ -0,0 +1,130 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func (s *ConfigTestSuite) TestLoadConfig_Default() {
+	// Given
+	s.T().Setenv(EnvDSN, "")
+
+	// When
+	config, err := LoadConfig()
+
+	// Then
+	s.NoError(err)
+	s.Equal(DefaultConfig(), config)
+}
+
+func (s *ConfigTestSuite) TestLoadConfig_Env() {
+	// Given
+	s.T().Setenv(EnvDSN, "file:/tmp/other.db")
+	s.T().Setenv(EnvWAL, "false")
+	s.T().Setenv(EnvBusyTimeout, "250ms")
+	s.T().Setenv(EnvMaxOpenConns, "4")
+
+	// When
+	config, err := LoadConfig()
+
+	// Then
+	s.NoError(err)
+	s.Equal(Config{DSN: "file:/tmp/other.db", BusyTimeout: 250 * time.Millisecond, MaxOpenConns: 4}, config)
+}
+
+func (s *ConfigTestSuite) TestLoadConfig_Invalid() {
+	testCases := []struct {
+		env   string
+		value string
+	}{
+		{EnvWAL, "maybe"},
+		{EnvBusyTimeout, "5"},
+		{EnvBusyTimeout, "-1s"},
+		{EnvMaxOpenConns, "-1"},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.env+"="+tc.value, func() {
+			s.T().Setenv(tc.env, tc.value)
+			_, err := LoadConfig()
+			s.ErrorContains(err, tc.env)
+		})
+	}
+}
+
+func (s *ConfigTestSuite) TestDialect() {
+	testCases := []struct {
+		dsn        string
+		want       Dialect
+		dataSource string
+	}{
+		{"file:/tmp/cake.db", SQLite, "file:/tmp/cake.db?_pragma=foreign_keys%281%29&_pragma=busy_timeout%280%29"},
+		{"postgres://cake@localhost/cake?sslmode=disable", Postgres, "postgres://cake@localhost/cake?sslmode=disable&timezone=UTC"},
+		{"postgresql://cake@localhost/cake?timezone=Asia%2FTaipei", Postgres, "postgresql://cake@localhost/cake?timezone=Asia%2FTaipei"},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.dsn, func() {
+			config := Config{DSN: tc.dsn}
+			s.Equal(tc.want, config.Dialect())
+			s.Equal(tc.dataSource, config.dataSource())
+		})
+	}
+}
+
+func (s *ConfigTestSuite) TestOpen() {
+	// Given
+	config := Config{
+		DSN:          "file:" + filepath.Join(s.T().TempDir(), "cake.db"),
+		WAL:          true,
+		BusyTimeout:  time.Second,
+		MaxOpenConns: 2,
+	}
+
+	// When
+	db, err := Open(config)
+
+	// Then
+	s.Require().NoError(err)
+	defer db.Close()
+	var journalMode string
+	s.NoError(db.Get(&journalMode, "PRAGMA journal_mode"))
+	s.Equal("wal", journalMode)
+	var busyTimeout, foreignKeys int
+	s.NoError(db.Get(&busyTimeout, "PRAGMA busy_timeout"))
+	s.Equal(1000, busyTimeout)
+	s.NoError(db.Get(&foreignKeys, "PRAGMA foreign_keys"))
+	s.Equal(1, foreignKeys)
+	s.Equal(2, db.Stats().MaxOpenConnections)
+}
+
+func (s *ConfigTestSuite) TestNew_SeparateDatabases() {
+	// Given
+	dir := s.T().TempDir()
+	a, err := New(Config{DSN: "file:" + filepath.Join(dir, "a.db")})
+	s.Require().NoError(err)
+	defer a.Close()
+	b, err := New(Config{DSN: "file:" + filepath.Join(dir, "b.db")})
+	s.Require().NoError(err)
+	defer b.Close()
+
+	// When
+	_, err = a.Exec("INSERT INTO tags (tag) VALUES ('Go')")
+	s.Require().NoError(err)
+
+	// Then
+	var count int
+	s.NoError(a.Get(&count, "SELECT COUNT(*) FROM tags"))
+	s.Equal(1, count)
+	s.NoError(b.Get(&count, "SELECT COUNT(*) FROM tags"))
+	s.Equal(0, count)
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -1,43 +1,187 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log/slog"
-	"os"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/uptrace/bun/driver/sqliteshim"
+	_ "github.com/lib/pq"
 )
 
 var (
-	db *DB
+	db     *DB
+	dbErr  error
+	dbOnce sync.Once
 )
 
+// DB is a database of either dialect. Queries are written with ? placeholders,
+// which are rebound to the placeholders of the dialect.
 type DB struct {
 	*sqlx.DB
+	Dialect Dialect
 }
 
+// Tx is a transaction of a DB, rebinding placeholders like it.
+type Tx struct {
+	*sqlx.Tx
+}
+
+// Connect returns the database shared by the repos of the process, opening it
+// with the configuration from the environment and applying the pending
+// migrations on first use.
 func Connect() (*DB, error) {
-	if db != nil {
-		return db, nil
+	dbOnce.Do(func() {
+		config, err := LoadConfig()
+		if err != nil {
+			slog.Error("failed to load database config", "err", err)
+			dbErr = err
+			return
+		}
+		db, dbErr = New(config)
+	})
+	return db, dbErr
+}
+
+// New opens a database and applies the pending migrations. Unlike Connect, it
+// returns a new instance on every call.
+func New(config Config) (*DB, error) {
+	conn, err := Open(config)
+	if err != nil {
+		return nil, err
 	}
-	// conn, err := sqlx.Connect(sqliteshim.ShimName, "file::memory:?cache=shared&_fk=1")
-	conn, err := sqlx.Connect(sqliteshim.ShimName, "file:cake.db?cache=shared&_fk=1")
+	migrator, err := NewMigrator(conn)
 	if err != nil {
-		slog.Error("failed to connect to database", "err", err)
+		conn.Close()
+		slog.Error("failed to load migrations", "err", err)
 		return nil, err
 	}
-	db = &DB{
-		DB: conn,
+	if _, err := migrator.Up(); err != nil {
+		conn.Close()
+		slog.Error("failed to migrate database", "err", err)
+		return nil, err
 	}
-	f, err := os.ReadFile("sql/schema.sql")
+	return conn, nil
+}
+
+// Open opens a database without migrating it.
+func Open(config Config) (*DB, error) {
+	dialect := config.Dialect()
+	conn, err := sqlx.Connect(dialect.driverName(), config.dataSource())
+	if err != nil {
+		slog.Error("failed to connect to database", "dialect", dialect, "err", err)
+		return nil, fmt.Errorf("failed to connect to %s database: %w", dialect, err)
+	}
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+	return &DB{DB: conn, Dialect: dialect}, nil
+}
+
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.Rebind(query), args...)
+}
+
+func (db *DB) MustExec(query string, args ...any) sql.Result {
+	return db.DB.MustExec(db.Rebind(query), args...)
+}
+
+func (db *DB) Queryx(query string, args ...any) (*sqlx.Rows, error) {
+	return db.DB.Queryx(db.Rebind(query), args...)
+}
+
+func (db *DB) QueryRowx(query string, args ...any) *sqlx.Row {
+	return db.DB.QueryRowx(db.Rebind(query), args...)
+}
+
+func (db *DB) Get(dest any, query string, args ...any) error {
+	return db.DB.Get(dest, db.Rebind(query), args...)
+}
+
+func (db *DB) Select(dest any, query string, args ...any) error {
+	return db.DB.Select(dest, db.Rebind(query), args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.Rebind(query), args...)
+}
+
+func (db *DB) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	return db.DB.QueryxContext(ctx, db.Rebind(query), args...)
+}
+
+func (db *DB) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	return db.DB.QueryRowxContext(ctx, db.Rebind(query), args...)
+}
+
+func (db *DB) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return db.DB.GetContext(ctx, dest, db.Rebind(query), args...)
+}
+
+func (db *DB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return db.DB.SelectContext(ctx, dest, db.Rebind(query), args...)
+}
+
+func (db *DB) Beginx() (*Tx, error) {
+	tx, err := db.DB.Beginx()
 	if err != nil {
-		slog.Error("failed to read schema.sql", "err", err)
 		return nil, err
 	}
-	_, err = db.Exec(string(f))
+	return &Tx{Tx: tx}, nil
+}
+
+func (db *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTxx(ctx, opts)
 	if err != nil {
-		slog.Error("failed to execute schema.sql", "err", err)
 		return nil, err
 	}
-	return db, nil
+	return &Tx{Tx: tx}, nil
+}
+
+func (db *DB) MustBegin() *Tx {
+	return &Tx{Tx: db.DB.MustBegin()}
+}
+
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.Rebind(query), args...)
+}
+
+func (tx *Tx) MustExec(query string, args ...any) sql.Result {
+	return tx.Tx.MustExec(tx.Rebind(query), args...)
+}
+
+func (tx *Tx) Queryx(query string, args ...any) (*sqlx.Rows, error) {
+	return tx.Tx.Queryx(tx.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRowx(query string, args ...any) *sqlx.Row {
+	return tx.Tx.QueryRowx(tx.Rebind(query), args...)
+}
+
+func (tx *Tx) Get(dest any, query string, args ...any) error {
+	return tx.Tx.Get(dest, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) Select(dest any, query string, args ...any) error {
+	return tx.Tx.Select(dest, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	return tx.Tx.QueryxContext(ctx, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	return tx.Tx.QueryRowxContext(ctx, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return tx.Tx.GetContext(ctx, dest, tx.Rebind(query), args...)
+}
+
+func (tx *Tx) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return tx.Tx.SelectContext(ctx, dest, tx.Rebind(query), args...)
 }
@@ -0,0 +1,169 @@
+// Package dbtest opens migrated databases of each dialect for the tests of
+// the repos.
+package dbtest
+
+import (
+	"bytes"
+	"cake-scraper/pkg/database"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+)
+
+const (
+	// EnvPostgresDSN is the environment variable of the PostgreSQL database
+	// the tests run against. When it is not set, an embedded PostgreSQL server
+	// is started for the tests instead, if it can be.
+	EnvPostgresDSN = "CAKE_TEST_POSTGRES_DSN"
+	// EnvSkipPostgres skips the PostgreSQL tests when set to a non-empty
+	// value, without trying to start the embedded server.
+	EnvSkipPostgres = "CAKE_TEST_SKIP_POSTGRES"
+)
+
+var (
+	embedded     *embeddedpostgres.EmbeddedPostgres
+	embeddedDir  string
+	embeddedDSN  string
+	embeddedErr  error
+	embeddedOnce sync.Once
+)
+
+// Main runs the tests of a package with PostgreSQL backends and stops the
+// embedded server once they are done. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(dbtest.Main(m))
+//	}
+func Main(m *testing.M) int {
+	code := m.Run()
+	if embedded != nil {
+		if err := embedded.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop embedded PostgreSQL: %v\n", err)
+		}
+	}
+	if embeddedDir != "" {
+		_ = os.RemoveAll(embeddedDir)
+	}
+	return code
+}
+
+// Backend opens a new, empty database of a dialect for a test.
+type Backend struct {
+	Name string
+	Open func(t testing.TB) *database.DB
+}
+
+// Backends returns a backend of each dialect.
+func Backends() []Backend {
+	return []Backend{
+		{Name: database.SQLite.String(), Open: OpenSQLite},
+		{Name: database.Postgres.String(), Open: OpenPostgres},
+	}
+}
+
+// OpenSQLite opens a SQLite database in a temporary directory.
+func OpenSQLite(t testing.TB) *database.DB {
+	t.Helper()
+	return open(t, database.Config{DSN: "file:" + filepath.Join(t.TempDir(), "cake.db")})
+}
+
+// OpenPostgres opens a new schema, dropped when the test ends, of the
+// PostgreSQL database in CAKE_TEST_POSTGRES_DSN, or of an embedded server if
+// it is not set. The test is skipped if CAKE_TEST_SKIP_POSTGRES is set.
+func OpenPostgres(t testing.TB) *database.DB {
+	t.Helper()
+	if os.Getenv(EnvSkipPostgres) != "" {
+		t.Skipf("%s is set", EnvSkipPostgres)
+	}
+	dsn := os.Getenv(EnvPostgresDSN)
+	if dsn == "" {
+		dsn = startEmbedded(t)
+	}
+	admin, err := database.Open(database.Config{DSN: dsn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Error(err)
+		}
+	})
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return open(t, database.Config{DSN: u.String()})
+}
+
+// startEmbedded starts the embedded PostgreSQL server shared by the tests of
+// the package, if it is not running yet, and returns its DSN. Its binaries
+// are downloaded on first use and cached in ~/.embedded-postgres-go. The test
+// is skipped if the server cannot start, e.g. without network access or as
+// root, so that the other backends still run.
+func startEmbedded(t testing.TB) string {
+	t.Helper()
+	embeddedOnce.Do(func() {
+		embeddedDSN, embeddedErr = runEmbedded()
+	})
+	if embeddedErr != nil {
+		t.Skipf("embedded PostgreSQL is unavailable, set %s to use another server: %v", EnvPostgresDSN, embeddedErr)
+	}
+	return embeddedDSN
+}
+
+func runEmbedded() (string, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	if embeddedDir, err = os.MkdirTemp("", "cake-postgres-"); err != nil {
+		return "", err
+	}
+	var logs bytes.Buffer
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V16).
+		Port(port).
+		RuntimePath(embeddedDir).
+		Logger(&logs)
+	server := embeddedpostgres.NewDatabase(config)
+	if err := server.Start(); err != nil {
+		return "", fmt.Errorf("%w\n%s", err, logs.String())
+	}
+	embedded = server
+	return config.GetConnectionURL() + "?sslmode=disable", nil
+}
+
+// freePort returns a TCP port nothing listens on.
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+func open(t testing.TB, config database.Config) *database.DB {
+	t.Helper()
+	db, err := database.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// dialectOf returns the dialect of the database dsn points to. PostgreSQL DSNs
+// are URLs like "postgres://user@host/db"; anything else is a SQLite file.
+func dialectOf(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return Postgres
+	}
+	return SQLite
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	default:
+		return "sqlite"
+	}
+}
+
+// driverName returns the database/sql driver of the dialect.
+func (d Dialect) driverName() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	default:
+		return sqliteshim.ShimName
+	}
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"cake-scraper/sql/migrations"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// migrationFile matches migration file names like "0002_job_tracking.up.sql".
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration and when it was applied, or zero if it is
+// pending.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// LoadMigrations returns the migrations in fsys by version.
+func LoadMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+	result := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		result = append(result, m)
+	}
+	slices.SortFunc(result, func(a, b *Migration) int { return a.Version - b.Version })
+	return result, nil
+}
+
+// Migrator applies and reverts migrations, recording the applied versions in
+// the schema_migrations table.
+type Migrator struct {
+	db         *DB
+	migrations []*Migration
+}
+
+// NewMigrator returns a migrator of the embedded migrations of the dialect of
+// db.
+func NewMigrator(db *DB) (*Migrator, error) {
+	fsys, err := fs.Sub(migrations.FS, db.Dialect.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	loaded, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: loaded}, nil
+}
+
+func (m *Migrator) init() error {
+	appliedAt := "TEXT"
+	if m.db.Dialect == Postgres {
+		appliedAt = "TIMESTAMP"
+	}
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at ` + appliedAt + ` NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// Status returns every migration, applied or pending, by version.
+func (m *Migrator) Status() ([]*MigrationStatus, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	var applied []struct {
+		Version   int  `db:"version"`
+		AppliedAt Time `db:"applied_at"`
+	}
+	if err := m.db.Select(&applied, "SELECT version, applied_at FROM schema_migrations"); err != nil {
+		return nil, fmt.Errorf("failed to select schema_migrations: %w", err)
+	}
+	appliedAt := map[int]time.Time{}
+	for _, a := range applied {
+		appliedAt[a.Version] = time.Time(a.AppliedAt)
+	}
+	status := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status = append(status, &MigrationStatus{Migration: *migration, AppliedAt: appliedAt[migration.Version]})
+	}
+	return status, nil
+}
+
+// Up applies the pending migrations in order, each in its own transaction,
+// and returns them.
+func (m *Migrator) Up() ([]*Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	applied := []*Migration{}
+	for _, s := range status {
+		if !s.AppliedAt.IsZero() {
+			continue
+		}
+		migration := s.Migration
+		err := m.apply(&migration, migration.Up, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, &migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the latest applied migration and returns it, or nil if none is
+// applied.
+func (m *Migrator) Down() (*Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(status) - 1; i >= 0; i-- {
+		if status[i].AppliedAt.IsZero() {
+			continue
+		}
+		migration := status[i].Migration
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		err := m.apply(&migration, migration.Down, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+		if err != nil {
+			return nil, err
+		}
+		return &migration, nil
+	}
+	return nil, nil
+}
+
+// apply runs script and records it with the record statement in one
+// transaction.
+func (m *Migrator) apply(migration *Migration, script, record string, args ...any) (err error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("failed to migrate %d_%s: %w", migration.Version, migration.Name, err)
+			return
+		}
+		err = tx.Commit()
+	}()
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(record, args...); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrateTestSuite struct {
+	suite.Suite
+	migrator *Migrator
+}
+
+func (s *MigrateTestSuite) SetupTest() {
+	db, err := Open(Config{DSN: "file:" + filepath.Join(s.T().TempDir(), "cake.db")})
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { db.Close() })
+	s.migrator, err = NewMigrator(db)
+	s.Require().NoError(err)
+}
+
+func (s *MigrateTestSuite) TestUp() {
+	// When
+	applied, err := s.migrator.Up()
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(len(s.migrator.migrations), len(applied))
+	status, err := s.migrator.Status()
+	s.Require().NoError(err)
+	for _, m := range status {
+		s.False(m.AppliedAt.IsZero(), "%d_%s is pending", m.Version, m.Name)
+	}
+	applied, err = s.migrator.Up()
+	s.NoError(err)
+	s.Empty(applied)
+}
+
+func (s *MigrateTestSuite) TestDown() {
+	// Given
+	_, err := s.migrator.Up()
+	s.Require().NoError(err)
+
+	// When
+	for i := len(s.migrator.migrations) - 1; i >= 0; i-- {
+		reverted, err := s.migrator.Down()
+		s.Require().NoError(err)
+		s.Equal(s.migrator.migrations[i].Version, reverted.Version)
+	}
+
+	// Then
+	reverted, err := s.migrator.Down()
+	s.NoError(err)
+	s.Nil(reverted)
+	var tables []string
+	s.NoError(s.migrator.db.Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"))
+	s.Equal([]string{"schema_migrations"}, tables)
+	applied, err := s.migrator.Up()
+	s.NoError(err)
+	s.Equal(len(s.migrator.migrations), len(applied))
+}
+
+func (s *MigrateTestSuite) TestUp_AdoptsBaselineDatabase() {
+	// Given a database created by the schema before migrations existed
+	baseline := s.migrator.migrations[0]
+	_, err := s.migrator.db.Exec(baseline.Up)
+	s.Require().NoError(err)
+	_, err = s.migrator.db.Exec("INSERT INTO jobs (link, title) VALUES ('https://x/1', 'Engineer')")
+	s.Require().NoError(err)
+
+	// When
+	_, err = s.migrator.Up()
+
+	// Then
+	s.Require().NoError(err)
+	var firstSeenAt string
+	s.NoError(s.migrator.db.Get(&firstSeenAt, "SELECT first_seen_at FROM jobs"))
+	s.NotEmpty(firstSeenAt)
+	var source string
+	s.NoError(s.migrator.db.Get(&source, "SELECT source FROM jobs"))
+	s.Equal("cake", source)
+	var matched int
+	s.NoError(s.migrator.db.Get(&matched, "SELECT COUNT(*) FROM jobs_fts WHERE jobs_fts MATCH 'engineer'"))
+	s.Equal(1, matched)
+}
+
+func (s *MigrateTestSuite) TestUp_BackfillsCompanies() {
+	// Given a baseline database with jobs of two companies and a job whose
+	// link has no company
+	baseline := s.migrator.migrations[0]
+	_, err := s.migrator.db.Exec(baseline.Up)
+	s.Require().NoError(err)
+	for _, j := range []struct{ link, company string }{
+		{"https://www.cake.me/companies/acme-labs/jobs/backend", "Acme Labs"},
+		{"https://www.cake.me/companies/acme-labs/jobs/frontend", "Acme Labs"},
+		{"https://www.cake.me/companies/pixel-cloud/jobs/designer", "Pixel Cloud"},
+		{"https://www.cake.me/jobs/unknown", "Unknown"},
+	} {
+		_, err = s.migrator.db.Exec("INSERT INTO jobs (link, title, company) VALUES (?, 'Engineer', ?)", j.link, j.company)
+		s.Require().NoError(err)
+	}
+
+	// When
+	_, err = s.migrator.Up()
+
+	// Then
+	s.Require().NoError(err)
+	var companies []struct {
+		Slug string `db:"slug"`
+		Name string `db:"name"`
+	}
+	s.NoError(s.migrator.db.Select(&companies, "SELECT slug, name FROM companies ORDER BY slug"))
+	s.Equal([]struct {
+		Slug string `db:"slug"`
+		Name string `db:"name"`
+	}{{"acme-labs", "Acme Labs"}, {"pixel-cloud", "Pixel Cloud"}}, companies)
+	var jobs []struct {
+		Link string  `db:"link"`
+		Slug *string `db:"slug"`
+	}
+	s.NoError(s.migrator.db.Select(&jobs, "SELECT j.link, c.slug FROM jobs AS j LEFT JOIN companies AS c ON j.company_id = c.id ORDER BY j.id"))
+	slugs := map[string]string{}
+	for _, j := range jobs {
+		slugs[j.Link] = ""
+		if j.Slug != nil {
+			slugs[j.Link] = *j.Slug
+		}
+	}
+	s.Equal(map[string]string{
+		"https://www.cake.me/companies/acme-labs/jobs/backend":    "acme-labs",
+		"https://www.cake.me/companies/acme-labs/jobs/frontend":   "acme-labs",
+		"https://www.cake.me/companies/pixel-cloud/jobs/designer": "pixel-cloud",
+		"https://www.cake.me/jobs/unknown":                        "",
+	}, slugs)
+}
+
+func TestMigrateTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
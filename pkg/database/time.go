@@ -0,0 +1,39 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Time is a timestamp in UTC, stored as TEXT in time.DateTime format by SQLite
+// and as a TIMESTAMP by PostgreSQL. The zero Time is stored as NULL.
+type Time time.Time
+
+func (t Time) Value() (driver.Value, error) {
+	if time.Time(t).IsZero() {
+		return nil, nil
+	}
+	return time.Time(t).UTC().Format(time.DateTime), nil
+}
+
+func (t *Time) Scan(v interface{}) error {
+	if v == nil {
+		*t = Time(time.Time{})
+		return nil
+	}
+	switch v := v.(type) {
+	case time.Time:
+		*t = Time(v.UTC())
+		return nil
+	case string:
+		tp, err := time.Parse(time.DateTime, v)
+		if err != nil {
+			return err
+		}
+		*t = Time(tp)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Time", v)
+	}
+}
@@ -0,0 +1,7 @@
+package dto
+
+type Category struct {
+	Name       string      `json:"name"`
+	ActiveJobs int         `json:"active_jobs"`
+	Subs       []*Category `json:"subs,omitempty"`
+}
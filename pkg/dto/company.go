@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type Company struct {
+	ID          int64      `json:"id"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	Industry    string     `json:"industry"`
+	Size        string     `json:"size"`
+	FoundedYear *int       `json:"founded_year"`
+	Location    string     `json:"location"`
+	Website     string     `json:"website"`
+	Benefits    []string   `json:"benefits"`
+	FetchedAt   *time.Time `json:"fetched_at"`
+}
@@ -1,24 +1,40 @@
 package dto
 
-import "cake-scraper/pkg/util"
+import (
+	"time"
+)
 
 type Job struct {
-	Company          string   `json:"company"`
-	Title            string   `json:"title"`
-	Link             string   `json:"link"`
-	MainCategory     string   `json:"main_category"`
-	SubCategory      string   `json:"sub_category"`
-	EmploymentType   string   `json:"employment_type"`
-	Seniority        string   `json:"seniority"`
-	Location         string   `json:"location"`
-	NumberToHire     int      `json:"number_to_hire"`
-	Experience       string   `json:"experience"`
-	Salary           string   `json:"salary"`
-	Remote           string   `json:"remote"`
-	InterviewProcess string   `json:"interview_process"`
-	JobDescription   string   `json:"job_description"`
-	Requirements     string   `json:"requirements"`
-	Tags             []string `json:"tags"`
+	ID               int64      `json:"id"`
+	Source           string     `json:"source"`
+	Company          string     `json:"company"`
+	CompanySlug      string     `json:"company_slug"`
+	Title            string     `json:"title"`
+	Link             string     `json:"link"`
+	MainCategory     string     `json:"main_category"`
+	SubCategory      string     `json:"sub_category"`
+	EmploymentType   string     `json:"employment_type"`
+	Seniority        string     `json:"seniority"`
+	Location         string     `json:"location"`
+	NumberToHire     int        `json:"number_to_hire"`
+	Experience       string     `json:"experience"`
+	ExperienceYears  *int       `json:"experience_years"`
+	Salary           string     `json:"salary"`
+	SalaryMin        *float64   `json:"salary_min"`
+	SalaryMax        *float64   `json:"salary_max"`
+	SalaryCurrency   string     `json:"salary_currency"`
+	SalaryPeriod     string     `json:"salary_period"`
+	SalaryAnnual     *float64   `json:"salary_annual"`
+	Remote           string     `json:"remote"`
+	InterviewProcess string     `json:"interview_process"`
+	JobDescription   string     `json:"job_description"`
+	Requirements     string     `json:"requirements"`
+	Tags             []string   `json:"tags"`
+	FirstSeenAt      time.Time  `json:"first_seen_at"`
+	LastSeenAt       time.Time  `json:"last_seen_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ClosedAt         *time.Time `json:"closed_at"`
+	ChangedAt        *time.Time `json:"changed_at"`
+	// Snippet is HTML with the search matches in <mark> elements.
+	Snippet string `json:"snippet,omitempty"`
 }
-
-type JobsPaginator = util.Paginator[*Job]
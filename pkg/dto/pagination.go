@@ -0,0 +1,18 @@
+package dto
+
+type Pagination struct {
+	Total      int64 `json:"total"`
+	Page       int64 `json:"page"`
+	PerPage    int64 `json:"per_page"`
+	TotalPages int64 `json:"total_pages"`
+	// Next and Prev are links to the next and previous pages, or nil on the
+	// last and first page.
+	Next *string `json:"next"`
+	Prev *string `json:"prev"`
+}
+
+// ParamError describes an invalid query parameter.
+type ParamError struct {
+	Parameter string `json:"parameter"`
+	Message   string `json:"message"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type Change struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type Revision struct {
+	ID        int64     `json:"id"`
+	RunID     *int64    `json:"run_id"`
+	ChangedAt time.Time `json:"changed_at"`
+	Changes   []Change  `json:"changes"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type Run struct {
+	ID             int64      `json:"id"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	Status         string     `json:"status"`
+	Sources        []string   `json:"sources"`
+	Professions    []string   `json:"professions"`
+	PagesRequested int        `json:"pages_requested"`
+	PagesVisited   int        `json:"pages_visited"`
+	JobsNew        int        `json:"jobs_new"`
+	JobsUpdated    int        `json:"jobs_updated"`
+	JobsUnchanged  int        `json:"jobs_unchanged"`
+	JobsSkipped    int        `json:"jobs_skipped"`
+	JobsClosed     int        `json:"jobs_closed"`
+	Errors         int        `json:"errors"`
+	ErrorMessages  []string   `json:"error_messages"`
+	Error          string     `json:"error"`
+	JobLinks       []string   `json:"job_links,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"cake-scraper/pkg/repo/jobrepo"
+	"time"
+)
+
+type Search struct {
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	Conditions   jobrepo.Conditions `json:"conditions"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	LastViewedAt *time.Time         `json:"last_viewed_at"`
+}
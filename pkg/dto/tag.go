@@ -0,0 +1,6 @@
+package dto
+
+type TagCount struct {
+	Tag  string `json:"tag"`
+	Jobs int    `json:"jobs"`
+}
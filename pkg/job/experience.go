@@ -0,0 +1,42 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidExperience = errors.New("invalid experience")
+	// experienceRegex matches requirements like "3 years of experience
+	// required", "5+ years", "1-3 years" and "36 months of experience
+	// required". Of a range only the lower bound is captured.
+	experienceRegex = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)(?:\s*[-~–]\s*\d+(?:\.\d+)?)?\s*\+?\s*(year|yr|month)s?\b`)
+	// noExperienceRegex matches requirements that ask for no experience.
+	noExperienceRegex = regexp.MustCompile(`(?i)^(no requirement|no experience|not required|none)\b`)
+)
+
+// ParseExperience returns the minimum years of experience a requirement such
+// as "3 years of experience required" asks for. Months are rounded up to whole
+// years, and requirements asking for no experience are 0.
+func ParseExperience(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if noExperienceRegex.MatchString(s) {
+		return 0, nil
+	}
+	match := experienceRegex.FindStringSubmatch(s)
+	if match == nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExperience, s)
+	}
+	amount, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExperience, s)
+	}
+	if strings.EqualFold(match[2], "month") {
+		amount /= 12
+	}
+	return int(math.Ceil(amount)), nil
+}
@@ -0,0 +1,57 @@
+package job_test
+
+import (
+	"cake-scraper/pkg/job"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExperienceTestSuite struct {
+	suite.Suite
+}
+
+func (s *ExperienceTestSuite) TestParseExperience() {
+	tests := []struct {
+		experience string
+		want       int
+	}{
+		{"3 years of experience required", 3},
+		{"1 year of experience required", 1},
+		{"5+ years", 5},
+		{"1-3 years of experience required", 1},
+		{"2 ~ 5 years", 2},
+		{"6–12 months", 1},
+		{"36 months of experience required", 3},
+		{"18 months of experience required", 2},
+		{"No requirement for relevant working experience", 0},
+	}
+	for _, tt := range tests {
+		s.Run(tt.experience, func() {
+			// When
+			got, err := job.ParseExperience(tt.experience)
+
+			// Then
+			if !s.NoError(err) {
+				return
+			}
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *ExperienceTestSuite) TestParseExperience_Invalid() {
+	for _, experience := range []string{"", "Experienced", "Senior level"} {
+		s.Run(experience, func() {
+			// When
+			_, err := job.ParseExperience(experience)
+
+			// Then
+			s.ErrorIs(err, job.ErrInvalidExperience)
+		})
+	}
+}
+
+func TestExperienceTestSuite(t *testing.T) {
+	suite.Run(t, new(ExperienceTestSuite))
+}
@@ -1,22 +1,58 @@
 package job
 
+import "time"
+
+// HighlightStart and HighlightEnd enclose the search matches in Job.Snippet.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
 type Job struct {
-	Company          string
-	Title            string
-	Link             string
-	MainCategory     string
-	SubCategory      string
-	EmploymentType   EmploymentType
-	Seniority        Seniority
-	Location         string
-	NumberToHire     int
-	Experience       string
-	Salary           string
+	ID      int64
+	Source  string
+	Company string
+	// CompanySlug identifies the company on the source, or is empty if the
+	// source has no company pages.
+	CompanySlug    string
+	Title          string
+	Link           string
+	MainCategory   string
+	SubCategory    string
+	EmploymentType EmploymentType
+	Seniority      Seniority
+	Location       string
+	NumberToHire   int
+	Experience     string
+	// ExperienceYears is the minimum years of experience parsed from
+	// Experience, or nil if it could not be.
+	ExperienceYears *int
+	Salary          string
+	// SalaryRange is parsed from Salary, or nil if it could not be.
+	SalaryRange      *SalaryRange
 	Remote           Remote
 	InterviewProcess string
 	JobDescription   string
 	Requirements     string
 	Tags             []string
+	// FirstSeenAt and LastSeenAt are when the job was first and last listed.
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+	// UpdatedAt is when the stored job was last written with new content.
+	UpdatedAt time.Time
+	// ClosedAt is when the job stopped being listed, or zero while it is open.
+	ClosedAt time.Time
+	// ChangedAt is when a change to the posting was last seen, or zero if it
+	// never changed.
+	ChangedAt time.Time
+	// Snippet is an excerpt of the job matching a search, or empty outside of
+	// search results.
+	Snippet string
+}
+
+// Closed reports whether the job is no longer listed.
+func (j *Job) Closed() bool {
+	return !j.ClosedAt.IsZero()
 }
 
 func New() *Job {
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// untrackedFields are the Job fields that describe the stored record rather
+// than the posting, or are derived from other fields, and are left out of
+// diffs.
+var untrackedFields = map[string]bool{
+	"ID":              true,
+	"CompanySlug":     true,
+	"SalaryRange":     true,
+	"ExperienceYears": true,
+	"FirstSeenAt":     true,
+	"LastSeenAt":      true,
+	"UpdatedAt":       true,
+	"ClosedAt":        true,
+	"ChangedAt":       true,
+	"Snippet":         true,
+}
+
+// Change is the old and new value of a changed field. Enum values are given
+// by name.
+type Change struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Revision is a set of changes seen when a job was scraped again.
+type Revision struct {
+	ID    int64
+	JobID int64
+	// RunID is the scrape run that saw the changes, or 0 if there was none.
+	RunID     int64
+	ChangedAt time.Time
+	Changes   []Change
+}
+
+// Diff returns the changes between two versions of a job, in field order.
+// Tags are compared as sets.
+func Diff(old, new *Job) []Change {
+	changes := []Change{}
+	oldValue, newValue := reflect.ValueOf(*old), reflect.ValueOf(*new)
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i).Name
+		if untrackedFields[field] {
+			continue
+		}
+		a, b := diffValue(oldValue.Field(i).Interface()), diffValue(newValue.Field(i).Interface())
+		if field == "Tags" {
+			a, b = sortedTags(old.Tags), sortedTags(new.Tags)
+		}
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, Change{Field: field, Old: a, New: b})
+		}
+	}
+	return changes
+}
+
+func diffValue(v any) any {
+	if stringer, ok := v.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return v
+}
+
+func sortedTags(tags []string) []string {
+	sorted := slices.Sorted(slices.Values(tags))
+	return slices.Compact(sorted)
+}
@@ -0,0 +1,72 @@
+package job_test
+
+import (
+	"cake-scraper/pkg/job"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RevisionTestSuite struct {
+	suite.Suite
+}
+
+func (s *RevisionTestSuite) TestDiff() {
+	// Given
+	old := &job.Job{
+		ID:        1,
+		Title:     "Backend Engineer",
+		Salary:    "40K ~ 60K TWD / month",
+		Seniority: job.EntryLevel,
+		Remote:    job.NoRemote,
+		Tags:      []string{"Go", "SQL"},
+	}
+	new := &job.Job{
+		Title:      "Backend Engineer",
+		Salary:     "50K ~ 70K TWD / month",
+		Seniority:  job.EntryLevel,
+		Remote:     job.FullRemote,
+		Tags:       []string{"SQL", "Go", "Go"},
+		LastSeenAt: time.Now(),
+	}
+
+	// When
+	changes := job.Diff(old, new)
+
+	// Then
+	s.Equal([]job.Change{
+		{Field: "Salary", Old: "40K ~ 60K TWD / month", New: "50K ~ 70K TWD / month"},
+		{Field: "Remote", Old: job.NoRemote.String(), New: job.FullRemote.String()},
+	}, changes)
+}
+
+func (s *RevisionTestSuite) TestDiff_Tags() {
+	// Given
+	old := &job.Job{Tags: []string{"Go"}}
+	new := &job.Job{Tags: []string{"Go", "Rust"}}
+
+	// When
+	changes := job.Diff(old, new)
+
+	// Then
+	s.Equal([]job.Change{
+		{Field: "Tags", Old: []string{"Go"}, New: []string{"Go", "Rust"}},
+	}, changes)
+}
+
+func (s *RevisionTestSuite) TestDiff_Unchanged() {
+	// Given
+	old := &job.Job{Title: "Backend Engineer", Tags: []string{}}
+	new := &job.Job{Title: "Backend Engineer"}
+
+	// When
+	changes := job.Diff(old, new)
+
+	// Then
+	s.Empty(changes)
+}
+
+func TestRevisionTestSuite(t *testing.T) {
+	suite.Run(t, new(RevisionTestSuite))
+}
@@ -0,0 +1,149 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type SalaryPeriod int
+
+const (
+	Hourly SalaryPeriod = iota
+	Monthly
+	Yearly
+	InvalidSalaryPeriod SalaryPeriod = -1
+)
+
+const (
+	hoursPerYear  = 2080
+	monthsPerYear = 12
+)
+
+var (
+	ErrInvalidSalary = errors.New("invalid salary")
+	// salaryRegex matches salaries like "40K ~ 70K TWD / month",
+	// "1.2M+ TWD / year" and "~ 800 TWD / hour".
+	salaryRegex = regexp.MustCompile(`(?i)^(?:(` + amountPattern + `)(?:\s*~\s*(` + amountPattern + `))?(\+)?|~\s*(` + amountPattern + `))\s+([A-Z]{3})\s*/\s*(\w+)$`)
+)
+
+const amountPattern = `\d[\d,]*(?:\.\d+)?\s*[KM]?`
+
+func NewSalaryPeriod(s string) SalaryPeriod {
+	switch strings.ToLower(s) {
+	case "hour":
+		return Hourly
+	case "month":
+		return Monthly
+	case "year":
+		return Yearly
+	default:
+		return InvalidSalaryPeriod
+	}
+}
+
+func (p SalaryPeriod) String() string {
+	switch p {
+	case Hourly:
+		return "hour"
+	case Monthly:
+		return "month"
+	case Yearly:
+		return "year"
+	default:
+		return "Invalid"
+	}
+}
+
+func (p SalaryPeriod) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + p.String() + `"`), nil
+}
+
+func (p *SalaryPeriod) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+	*p = NewSalaryPeriod(str)
+	return nil
+}
+
+// SalaryRange is a parsed salary. Min or Max is 0 when the range is open on
+// that side.
+type SalaryRange struct {
+	Min      float64
+	Max      float64
+	Currency string
+	Period   SalaryPeriod
+}
+
+// ParseSalary parses a salary as shown on Cake, e.g. "40K ~ 70K TWD / month".
+func ParseSalary(s string) (*SalaryRange, error) {
+	match := salaryRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSalary, s)
+	}
+	salary := &SalaryRange{
+		Currency: strings.ToUpper(match[5]),
+		Period:   NewSalaryPeriod(match[6]),
+	}
+	if salary.Period == InvalidSalaryPeriod {
+		return nil, fmt.Errorf("%w: unknown period in %q", ErrInvalidSalary, s)
+	}
+	var err error
+	if salary.Min, err = parseAmount(match[1]); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSalary, s)
+	}
+	if salary.Max, err = parseAmount(match[2] + match[4]); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSalary, s)
+	}
+	if match[2] == "" && match[3] == "" && match[4] == "" {
+		salary.Max = salary.Min
+	}
+	if salary.Max != 0 && salary.Min > salary.Max {
+		return nil, fmt.Errorf("%w: minimum above maximum in %q", ErrInvalidSalary, s)
+	}
+	return salary, nil
+}
+
+// parseAmount parses an amount like "1,200", "40K" or "1.2M". An empty amount
+// is 0.
+func parseAmount(s string) (float64, error) {
+	s = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), ",", ""))
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier, s = 1e3, strings.TrimSpace(strings.TrimSuffix(s, "K"))
+	case strings.HasSuffix(s, "M"):
+		multiplier, s = 1e6, strings.TrimSpace(strings.TrimSuffix(s, "M"))
+	}
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return amount * multiplier, nil
+}
+
+// Annual returns the middle of the range normalized to a year, or the known
+// bound of an open range.
+func (s *SalaryRange) Annual() float64 {
+	amount := (s.Min + s.Max) / 2
+	if s.Min == 0 || s.Max == 0 {
+		amount = s.Min + s.Max
+	}
+	switch s.Period {
+	case Hourly:
+		return amount * hoursPerYear
+	case Monthly:
+		return amount * monthsPerYear
+	default:
+		return amount
+	}
+}
@@ -0,0 +1,62 @@
+package job_test
+
+import (
+	"cake-scraper/pkg/job"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SalaryTestSuite struct {
+	suite.Suite
+}
+
+func (s *SalaryTestSuite) TestParseSalary() {
+	tests := []struct {
+		salary string
+		want   job.SalaryRange
+		annual float64
+	}{
+		{"40K ~ 70K TWD / month", job.SalaryRange{Min: 40000, Max: 70000, Currency: "TWD", Period: job.Monthly}, 660000},
+		{"1.2M ~ 1.8M TWD / year", job.SalaryRange{Min: 1200000, Max: 1800000, Currency: "TWD", Period: job.Yearly}, 1500000},
+		{"1.2M+ TWD / year", job.SalaryRange{Min: 1200000, Currency: "TWD", Period: job.Yearly}, 1200000},
+		{"~ 70K USD / month", job.SalaryRange{Max: 70000, Currency: "USD", Period: job.Monthly}, 840000},
+		{"800 TWD / hour", job.SalaryRange{Min: 800, Max: 800, Currency: "TWD", Period: job.Hourly}, 1664000},
+		{"60,000 ~ 90,000 twd / Month", job.SalaryRange{Min: 60000, Max: 90000, Currency: "TWD", Period: job.Monthly}, 900000},
+	}
+	for _, tt := range tests {
+		s.Run(tt.salary, func() {
+			// When
+			got, err := job.ParseSalary(tt.salary)
+
+			// Then
+			if !s.NoError(err) {
+				return
+			}
+			s.Equal(tt.want, *got)
+			s.InDelta(tt.annual, got.Annual(), 0.001)
+		})
+	}
+}
+
+func (s *SalaryTestSuite) TestParseSalary_Invalid() {
+	for _, salary := range []string{
+		"",
+		"Negotiable",
+		"40K ~ 70K TWD",
+		"40K ~ 70K TWD / week",
+		"70K ~ 40K TWD / month",
+	} {
+		s.Run(salary, func() {
+			// When
+			_, err := job.ParseSalary(salary)
+
+			// Then
+			s.ErrorIs(err, job.ErrInvalidSalary)
+		})
+	}
+}
+
+func TestSalaryTestSuite(t *testing.T) {
+	suite.Run(t, new(SalaryTestSuite))
+}
@@ -0,0 +1,7 @@
+package job
+
+// TagCount is the number of jobs with a tag.
+type TagCount struct {
+	Tag  string
+	Jobs int
+}
@@ -80,3 +80,30 @@ func LoadLocations() []*Location {
 	})
 	return locations
 }
+
+// Find returns the locations named by query, either a zip code or an address
+// naming the location first, such as "Taipei City" or "East District, Tainan
+// City". Names are compared case-insensitively, and an ambiguous name returns
+// every location it names.
+func Find(query string) []*Location {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	queryTokens := strings.Split(query, ",")
+	return util.Filter(LoadLocations(), func(l *Location) bool {
+		if l.ZipCode == query {
+			return true
+		}
+		addressTokens := strings.Split(l.Address(), ", ")
+		if len(queryTokens) > len(addressTokens) {
+			return false
+		}
+		for i, token := range queryTokens {
+			if !strings.EqualFold(strings.TrimSpace(token), addressTokens[i]) {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,60 @@
+package location_test
+
+import (
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LocationTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LocationTestSuite) TestFind() {
+	testcases := []struct {
+		query string
+		want  []string
+	}{
+		{
+			query: "Taipei City",
+			want:  []string{"Taipei City, Taiwan"},
+		},
+		{
+			query: "taiwan",
+			want:  []string{"Taiwan"},
+		},
+		{
+			query: "Zhongzheng District, Taipei City",
+			want:  []string{"Zhongzheng District, Taipei City, Taiwan"},
+		},
+		// Ambiguous district
+		{
+			query: "East District",
+			want: []string{
+				"East District, Hsinchu City, Taiwan",
+				"East District, Taichung City, Taiwan",
+				"East District, Chiayi City, Taiwan",
+				"East District, Tainan City, Taiwan",
+			},
+		},
+		// Zip code
+		{
+			query: "100",
+			want:  []string{"Zhongzheng District, Taipei City, Taiwan"},
+		},
+		{
+			query: "Atlantis",
+			want:  []string{},
+		},
+	}
+	for _, tc := range testcases {
+		addresses := util.Map(location.Find(tc.query), (*location.Location).Address)
+		suite.ElementsMatch(tc.want, addresses, tc.query)
+	}
+}
+
+func TestLocationTestSuite(t *testing.T) {
+	suite.Run(t, new(LocationTestSuite))
+}
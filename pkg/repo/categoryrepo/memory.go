@@ -0,0 +1,62 @@
+package categoryrepo
+
+import (
+	"cake-scraper/pkg/category"
+	"cake-scraper/pkg/repo/jobrepo"
+	"cmp"
+	"context"
+	"slices"
+)
+
+var (
+	_ CategoryRepo = (*memoryCategoryRepo)(nil)
+)
+
+// memoryCategoryRepo derives the categories from the jobs of a job repo, as
+// the database does from the categories of the saved jobs.
+type memoryCategoryRepo struct {
+	jobRepo jobrepo.JobRepo
+}
+
+func NewMemoryCategoryRepo(jobRepo jobrepo.JobRepo) *memoryCategoryRepo {
+	return &memoryCategoryRepo{jobRepo: jobRepo}
+}
+
+func (r *memoryCategoryRepo) FindTree(ctx context.Context) ([]*category.Category, error) {
+	jobs, err := r.jobRepo.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	type key struct{ main, sub string }
+	counts := map[key]int{}
+	for _, j := range jobs {
+		if j.MainCategory == "" {
+			continue
+		}
+		active := 0
+		if j.ClosedAt.IsZero() {
+			active = 1
+		}
+		// The categories of closed jobs are listed too, with no active jobs.
+		counts[key{j.MainCategory, j.SubCategory}] += active
+	}
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(cmp.Compare(a.main, b.main), cmp.Compare(a.sub, b.sub))
+	})
+	tree := []*category.Category{}
+	for _, k := range keys {
+		if len(tree) == 0 || tree[len(tree)-1].Name != k.main {
+			tree = append(tree, &category.Category{Name: k.main, Subs: []*category.Category{}})
+		}
+		main := tree[len(tree)-1]
+		main.ActiveJobs += counts[k]
+		if k.sub != "" {
+			main.Subs = append(main.Subs, &category.Category{Name: k.sub, ActiveJobs: counts[k]})
+		}
+	}
+	return tree, nil
+}
@@ -0,0 +1,71 @@
+package categoryrepo
+
+import (
+	"cake-scraper/pkg/category"
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/util"
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	_ CategoryRepo = (*categoryRepoImpl)(nil)
+)
+
+type CategoryCountPo struct {
+	Main       string `db:"main"`
+	Sub        string `db:"sub"`
+	ActiveJobs int64  `db:"active_jobs"`
+}
+
+type CategoryRepo interface {
+	// FindTree returns the main categories with their sub categories, by name.
+	FindTree(ctx context.Context) ([]*category.Category, error)
+}
+
+type categoryRepoImpl struct {
+	db *database.DB
+}
+
+func NewCategoryRepo() *categoryRepoImpl {
+	db, err := database.Connect()
+	util.PanicError(err)
+	return &categoryRepoImpl{db: db}
+}
+
+// NewCategoryRepoWithDB returns a repo over db instead of the shared database.
+func NewCategoryRepoWithDB(db *database.DB) *categoryRepoImpl {
+	return &categoryRepoImpl{db: db}
+}
+
+func (r *categoryRepoImpl) FindTree(ctx context.Context) ([]*category.Category, error) {
+	sql, args, err := sq.Select("c.main", "c.sub", "COUNT(j.id) AS active_jobs").
+		From("categories AS c").
+		LeftJoin("jobs_categories AS jc ON jc.category_id = c.id").
+		LeftJoin("jobs AS j ON jc.job_id = j.id AND j.closed_at IS NULL").
+		GroupBy("c.main", "c.sub").
+		OrderBy("c.main", "c.sub").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*CategoryCountPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select categories: %w", err)
+	}
+	tree := []*category.Category{}
+	for _, po := range pos {
+		if len(tree) == 0 || tree[len(tree)-1].Name != po.Main {
+			tree = append(tree, &category.Category{Name: po.Main, Subs: []*category.Category{}})
+		}
+		main := tree[len(tree)-1]
+		main.ActiveJobs += int(po.ActiveJobs)
+		// Jobs without a sub category count towards the main category only.
+		if po.Sub != "" {
+			main.Subs = append(main.Subs, &category.Category{Name: po.Sub, ActiveJobs: int(po.ActiveJobs)})
+		}
+	}
+	return tree, nil
+}
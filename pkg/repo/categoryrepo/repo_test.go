@@ -0,0 +1,111 @@
+package categoryrepo
+
+import (
+	"cake-scraper/pkg/category"
+	"cake-scraper/pkg/database/dbtest"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/jobrepo"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// CategoryRepoTestSuite is the contract every CategoryRepo implementation
+// must meet. It runs against a new repo of one implementation per test, with
+// the job repo whose jobs are categorized.
+type CategoryRepoTestSuite struct {
+	suite.Suite
+	newRepos func(t testing.TB) (CategoryRepo, jobrepo.JobRepo)
+	repo     CategoryRepo
+	jobRepo  jobrepo.JobRepo
+}
+
+var ctx = context.Background()
+
+func (s *CategoryRepoTestSuite) SetupTest() {
+	s.repo, s.jobRepo = s.newRepos(s.T())
+}
+
+func (s *CategoryRepoTestSuite) saveJob(link, mainCategory, subCategory string) {
+	j := job.New()
+	j.Source = "cake"
+	j.Link = link
+	j.Company = "Acme"
+	j.MainCategory, j.SubCategory = mainCategory, subCategory
+	_, err := s.jobRepo.Save(ctx, j, 0)
+	s.Require().NoError(err)
+}
+
+func (s *CategoryRepoTestSuite) TestFindTree() {
+	// Given
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-1", "Software", "Backend")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/backend-2", "Software", "Backend")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/frontend", "Software", "Frontend")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/analyst", "Data", "")
+	s.saveJob("https://www.cake.me/companies/acme/jobs/designer", "Design", "UI")
+	_, err := s.jobRepo.Close(ctx, []string{
+		"https://www.cake.me/companies/acme/jobs/backend-2",
+		"https://www.cake.me/companies/acme/jobs/designer",
+	})
+	s.Require().NoError(err)
+
+	// When
+	tree, err := s.repo.FindTree(ctx)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal([]*category.Category{
+		{Name: "Data", ActiveJobs: 1, Subs: []*category.Category{}},
+		{Name: "Design", ActiveJobs: 0, Subs: []*category.Category{
+			{Name: "UI", ActiveJobs: 0},
+		}},
+		{Name: "Software", ActiveJobs: 2, Subs: []*category.Category{
+			{Name: "Backend", ActiveJobs: 1},
+			{Name: "Frontend", ActiveJobs: 1},
+		}},
+	}, tree)
+}
+
+func (s *CategoryRepoTestSuite) TestFindTree_Empty() {
+	// When
+	tree, err := s.repo.FindTree(ctx)
+
+	// Then
+	s.Require().NoError(err)
+	s.Empty(tree)
+}
+
+func (s *CategoryRepoTestSuite) TestFindTree_CanceledContext() {
+	// Given
+	canceled, cancel := context.WithCancel(ctx)
+
+	// When
+	cancel()
+	_, err := s.repo.FindTree(canceled)
+
+	// Then
+	s.ErrorIs(err, context.Canceled)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestCategoryRepoTestSuite(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		suite.Run(t, &CategoryRepoTestSuite{newRepos: func(t testing.TB) (CategoryRepo, jobrepo.JobRepo) {
+			jobRepo := jobrepo.NewMemoryJobRepo()
+			return NewMemoryCategoryRepo(jobRepo), jobRepo
+		}})
+	})
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &CategoryRepoTestSuite{newRepos: func(t testing.TB) (CategoryRepo, jobrepo.JobRepo) {
+				db := backend.Open(t)
+				return NewCategoryRepoWithDB(db), jobrepo.NewJobRepoWithDB(db)
+			}})
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package companyrepo
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/util"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ CompanyRepo = (*memoryCompanyRepo)(nil)
+)
+
+// memoryCompanyRepo keeps companies in memory, in the order they were first
+// saved.
+type memoryCompanyRepo struct {
+	mu        sync.RWMutex
+	companies []*company.Company
+}
+
+func NewMemoryCompanyRepo() *memoryCompanyRepo {
+	return &memoryCompanyRepo{}
+}
+
+func cloneCompany(c *company.Company) *company.Company {
+	clone := *c
+	clone.Benefits = append([]string{}, c.Benefits...)
+	return &clone
+}
+
+// bySlug returns the stored company with the given slug, or nil. r.mu must
+// be held.
+func (r *memoryCompanyRepo) bySlug(slug string) *company.Company {
+	for _, c := range r.companies {
+		if c.Slug == slug {
+			return c
+		}
+	}
+	return nil
+}
+
+func (r *memoryCompanyRepo) FindPaginated(ctx context.Context, page, perPage int64) (util.FalliblePaginator[*company.Company], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	companies := util.Map(r.companies, cloneCompany)
+	r.mu.RUnlock()
+	slices.SortStableFunc(companies, func(a, b *company.Company) int {
+		return cmp.Or(
+			strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*company.Company, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(offset+limit, int64(len(companies)))
+		return companies[min(offset, end):end], nil
+	}, page, perPage, int64(len(companies))), nil
+}
+
+func (r *memoryCompanyRepo) FindBySlug(ctx context.Context, slug string) (*company.Company, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c := r.bySlug(slug); c != nil {
+		return cloneCompany(c), nil
+	}
+	return nil, nil
+}
+
+func (r *memoryCompanyRepo) LastFetched(ctx context.Context, slugs []string) (map[string]time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lastFetched := map[string]time.Time{}
+	for _, slug := range slugs {
+		if c := r.bySlug(slug); c != nil && c.Fetched() {
+			lastFetched[slug] = c.FetchedAt
+		}
+	}
+	return lastFetched, nil
+}
+
+func (r *memoryCompanyRepo) Save(ctx context.Context, c *company.Company) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := cloneCompany(c)
+	// The current time at the precision the database stores.
+	saved.FetchedAt = time.Now().UTC().Truncate(time.Second)
+	if stored := r.bySlug(c.Slug); stored != nil {
+		saved.ID = stored.ID
+		*stored = *saved
+	} else {
+		saved.ID = int64(len(r.companies) + 1)
+		r.companies = append(r.companies, saved)
+	}
+	c.ID = saved.ID
+	return nil
+}
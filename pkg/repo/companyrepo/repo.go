@@ -0,0 +1,230 @@
+package companyrepo
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ CompanyRepo = (*companyRepoImpl)(nil)
+)
+
+// maxChunkSize bounds the number of values bound in a single IN clause.
+const maxChunkSize = 500
+
+type CompanyPo struct {
+	ID          int64         `db:"id"`
+	Slug        string        `db:"slug"`
+	Name        string        `db:"name"`
+	Industry    string        `db:"industry"`
+	Size        string        `db:"size"`
+	FoundedYear int64         `db:"founded_year"`
+	Location    string        `db:"location"`
+	Website     string        `db:"website"`
+	Benefits    string        `db:"benefits"`
+	CreatedAt   database.Time `db:"created_at"`
+	UpdatedAt   database.Time `db:"updated_at"`
+	FetchedAt   database.Time `db:"fetched_at"`
+}
+
+type CompanyRepo interface {
+	// FindPaginated returns the given page of the companies ordered by name,
+	// fetched on demand.
+	FindPaginated(ctx context.Context, page, perPage int64) (util.FalliblePaginator[*company.Company], error)
+	// FindBySlug returns the company with the given slug, or nil if there is
+	// none.
+	FindBySlug(ctx context.Context, slug string) (*company.Company, error)
+	// LastFetched returns when the profiles of the companies with the given
+	// slugs were last scraped. Companies never scraped are left out.
+	LastFetched(ctx context.Context, slugs []string) (map[string]time.Time, error)
+	// Save upserts the scraped profile of the company by slug and sets its ID.
+	Save(ctx context.Context, c *company.Company) error
+}
+
+type companyRepoImpl struct {
+	db *database.DB
+}
+
+func (p *CompanyPo) ToCompany() (*company.Company, error) {
+	c := &company.Company{
+		ID:          p.ID,
+		Slug:        p.Slug,
+		Name:        p.Name,
+		Industry:    p.Industry,
+		Size:        p.Size,
+		FoundedYear: int(p.FoundedYear),
+		Location:    p.Location,
+		Website:     p.Website,
+		FetchedAt:   time.Time(p.FetchedAt),
+	}
+	if err := json.Unmarshal([]byte(p.Benefits), &c.Benefits); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal benefits: %w", err)
+	}
+	return c, nil
+}
+
+func NewCompanyRepo() *companyRepoImpl {
+	db, err := database.Connect()
+	util.PanicError(err)
+	return &companyRepoImpl{db: db}
+}
+
+// NewCompanyRepoWithDB returns a repo over db instead of the shared database.
+func NewCompanyRepoWithDB(db *database.DB) *companyRepoImpl {
+	return &companyRepoImpl{db: db}
+}
+
+// SaveSlug upserts the company with the given slug, named name unless its
+// profile was scraped, and returns its id. Jobs save their company with it
+// before its profile is scraped.
+func SaveSlug(ctx context.Context, tx sqlx.QueryerContext, slug, name string) (int64, error) {
+	sql, args, err := sq.Insert("companies").
+		Columns("slug", "name").
+		Values(slug, name).
+		Suffix(`
+			ON CONFLICT(slug) DO UPDATE SET
+				name = CASE WHEN companies.fetched_at IS NULL THEN EXCLUDED.name ELSE companies.name END
+		`).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if err := sqlx.GetContext(ctx, tx, &id, sql, args...); err != nil {
+		return 0, fmt.Errorf("failed to insert company: %w", err)
+	}
+	return id, nil
+}
+
+func toCompanies(pos []*CompanyPo) ([]*company.Company, error) {
+	companies := make([]*company.Company, 0, len(pos))
+	for _, po := range pos {
+		c, err := po.ToCompany()
+		if err != nil {
+			return nil, err
+		}
+		companies = append(companies, c)
+	}
+	return companies, nil
+}
+
+func (r *companyRepoImpl) FindPaginated(ctx context.Context, page, perPage int64) (util.FalliblePaginator[*company.Company], error) {
+	var total int64
+	sql, args, err := sq.Select("COUNT(*)").
+		From("companies").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.GetContext(ctx, &total, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to count companies: %w", err)
+	}
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*company.Company, error) {
+		sql, args, err := sq.Select("*").
+			From("companies").
+			OrderBy("LOWER(name)", "id").
+			Offset(uint64(offset)).
+			Limit(uint64(limit)).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		var pos []*CompanyPo
+		if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select companies: %w", err)
+		}
+		return toCompanies(pos)
+	}, page, perPage, total), nil
+}
+
+func (r *companyRepoImpl) FindBySlug(ctx context.Context, slug string) (*company.Company, error) {
+	sql, args, err := sq.Select("*").
+		From("companies").
+		Where(sq.Eq{"slug": slug}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*CompanyPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select company: %w", err)
+	}
+	if len(pos) == 0 {
+		return nil, nil
+	}
+	return pos[0].ToCompany()
+}
+
+func (r *companyRepoImpl) LastFetched(ctx context.Context, slugs []string) (map[string]time.Time, error) {
+	lastFetched := map[string]time.Time{}
+	for _, chunk := range util.Chunk(slugs, maxChunkSize) {
+		sql, args, err := sq.Select("slug", "fetched_at").
+			From("companies").
+			Where(sq.Eq{"slug": chunk}).
+			Where(sq.NotEq{"fetched_at": nil}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		var rows []struct {
+			Slug      string        `db:"slug"`
+			FetchedAt database.Time `db:"fetched_at"`
+		}
+		if err := r.db.SelectContext(ctx, &rows, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select companies: %w", err)
+		}
+		for _, row := range rows {
+			lastFetched[row.Slug] = time.Time(row.FetchedAt)
+		}
+	}
+	return lastFetched, nil
+}
+
+func (r *companyRepoImpl) Save(ctx context.Context, c *company.Company) error {
+	benefits, err := json.Marshal(c.Benefits)
+	if err != nil {
+		return err
+	}
+	sql, args, err := sq.Insert("companies").
+		SetMap(map[string]interface{}{
+			"slug":         c.Slug,
+			"name":         c.Name,
+			"industry":     c.Industry,
+			"size":         c.Size,
+			"founded_year": c.FoundedYear,
+			"location":     c.Location,
+			"website":      c.Website,
+			"benefits":     string(benefits),
+			"fetched_at":   sq.Expr("CURRENT_TIMESTAMP"),
+		}).
+		Suffix(`
+			ON CONFLICT(slug) DO UPDATE SET
+				name = EXCLUDED.name,
+				industry = EXCLUDED.industry,
+				size = EXCLUDED.size,
+				founded_year = EXCLUDED.founded_year,
+				location = EXCLUDED.location,
+				website = EXCLUDED.website,
+				benefits = EXCLUDED.benefits,
+				updated_at = CURRENT_TIMESTAMP,
+				fetched_at = EXCLUDED.fetched_at
+		`).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if err := r.db.GetContext(ctx, &c.ID, sql, args...); err != nil {
+		return fmt.Errorf("failed to insert company: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package companyrepo
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/database/dbtest"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// CompanyRepoTestSuite is the contract every CompanyRepo implementation must
+// meet. It runs against a new repo of one implementation per test.
+type CompanyRepoTestSuite struct {
+	suite.Suite
+	newRepo func(t testing.TB) CompanyRepo
+	repo    CompanyRepo
+}
+
+var ctx = context.Background()
+
+func (s *CompanyRepoTestSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+}
+
+func (s *CompanyRepoTestSuite) newCompany(slug, name string) *company.Company {
+	c := company.New(slug)
+	c.Name = name
+	c.Industry = "Software"
+	c.Size = "51-200"
+	c.FoundedYear = 2015
+	c.Location = "Taipei City, Taiwan"
+	c.Website = "https://" + slug + ".example.com"
+	c.Benefits = []string{"Remote work", "Stock options"}
+	return c
+}
+
+func (s *CompanyRepoTestSuite) TestSave() {
+	// Given
+	c := s.newCompany("acme-labs", "Acme Labs")
+	s.Require().NoError(s.repo.Save(ctx, c))
+	id := c.ID
+
+	// When
+	c.Size = "201-500"
+	c.Benefits = []string{"Remote work"}
+	err := s.repo.Save(ctx, c)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(id, c.ID)
+	stored, err := s.repo.FindBySlug(ctx, "acme-labs")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.True(stored.Fetched())
+	stored.FetchedAt = c.FetchedAt
+	s.Equal(c, stored)
+}
+
+func (s *CompanyRepoTestSuite) TestFindBySlug_NotFound() {
+	// When
+	c, err := s.repo.FindBySlug(ctx, "acme-labs")
+
+	// Then
+	s.Require().NoError(err)
+	s.Nil(c)
+}
+
+func (s *CompanyRepoTestSuite) TestFindPaginated() {
+	// Given
+	s.Require().NoError(s.repo.Save(ctx, s.newCompany("pixel-cloud", "Pixel Cloud")))
+	s.Require().NoError(s.repo.Save(ctx, s.newCompany("acme-labs", "acme labs")))
+	s.Require().NoError(s.repo.Save(ctx, s.newCompany("formosa-data", "Formosa Data")))
+
+	// When
+	paginator, err := s.repo.FindPaginated(ctx, 1, 2)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(int64(3), paginator.Total())
+	companies, err := paginator.Items()
+	s.Require().NoError(err)
+	s.Require().Len(companies, 2)
+	s.Equal("acme-labs", companies[0].Slug)
+	s.Equal("formosa-data", companies[1].Slug)
+}
+
+func (s *CompanyRepoTestSuite) TestLastFetched() {
+	// Given
+	s.Require().NoError(s.repo.Save(ctx, s.newCompany("acme-labs", "Acme Labs")))
+
+	// When
+	lastFetched, err := s.repo.LastFetched(ctx, []string{"acme-labs", "pixel-cloud"})
+
+	// Then
+	s.Require().NoError(err)
+	s.Len(lastFetched, 1)
+	s.False(lastFetched["acme-labs"].IsZero())
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestCompanyRepoTestSuite(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		suite.Run(t, &CompanyRepoTestSuite{newRepo: func(t testing.TB) CompanyRepo {
+			return NewMemoryCompanyRepo()
+		}})
+	})
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &CompanyRepoTestSuite{newRepo: func(t testing.TB) CompanyRepo {
+				return NewCompanyRepoWithDB(backend.Open(t))
+			}})
+		})
+	}
+}
+
+func TestSaveSlug(t *testing.T) {
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			// Given
+			db := backend.Open(t)
+			repo := NewCompanyRepoWithDB(db)
+			id, err := SaveSlug(ctx, db, "acme-labs", "Acme")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// When
+			lastFetched, err := repo.LastFetched(ctx, []string{"acme-labs"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := company.New("acme-labs")
+			c.Name = "Acme Labs"
+			if err := repo.Save(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+			renamed, err := SaveSlug(ctx, db, "acme-labs", "Acme")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if len(lastFetched) != 0 {
+				t.Errorf("LastFetched = %v, want no scraped companies", lastFetched)
+			}
+			if c.ID != id || renamed != id {
+				t.Errorf("ids = %d, %d, want %d", c.ID, renamed, id)
+			}
+			stored, err := repo.FindBySlug(ctx, "acme-labs")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Name != "Acme Labs" {
+				t.Errorf("Name = %q, want the scraped name kept", stored.Name)
+			}
+		})
+	}
+}
@@ -1,18 +1,78 @@
 package jobrepo
 
 import (
+	"cake-scraper/pkg/database"
 	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/tag"
+	"cake-scraper/pkg/util"
+	"slices"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 )
 
+// state filters jobs by whether they are still listed.
+type state int
+
+const (
+	anyState state = iota
+	activeState
+	closedState
+)
+
+// timeRange is a range of timestamps, from inclusive and to exclusive. A zero
+// bound is open.
+type timeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+func (r timeRange) apply(builder sq.SelectBuilder, column string) sq.SelectBuilder {
+	if !r.from.IsZero() {
+		builder = builder.Where(sq.GtOrEq{column: Time(r.from)})
+	}
+	if !r.to.IsZero() {
+		builder = builder.Where(sq.Lt{column: Time(r.to)})
+	}
+	return builder
+}
+
+// intersect returns the range of the timestamps in both r and other.
+func (r timeRange) intersect(other timeRange) timeRange {
+	if other.from.After(r.from) {
+		r.from = other.from
+	}
+	if !other.to.IsZero() && (r.to.IsZero() || other.to.Before(r.to)) {
+		r.to = other.to
+	}
+	return r
+}
+
 type Conditions struct {
+	state           state
+	firstSeen       timeRange
+	updated         timeRange
+	sources         []string
 	company         string
+	companySlug     string
 	title           string
+	location        string
+	places          []*location.Location
+	mainCategories  []string
+	subCategories   []string
 	employmentTypes []job.EmploymentType
 	seniorities     []job.Seniority
 	remotes         []job.Remote
 	tags            []string
+	allTags         []string
+	noTags          []string
+	salaryMin       float64
+	salaryMax       float64
+	salaryCurrency  []string
+	maxExperience   *int
+	search          string
+	sorts           []Sort
 }
 
 func NewConditions() Conditions {
@@ -21,21 +81,124 @@ func NewConditions() Conditions {
 
 func (c Conditions) Clone() Conditions {
 	return Conditions{
+		state:           c.state,
+		firstSeen:       c.firstSeen,
+		updated:         c.updated,
+		sources:         append([]string{}, c.sources...),
 		company:         c.company,
+		companySlug:     c.companySlug,
 		title:           c.title,
+		location:        c.location,
+		places:          append([]*location.Location{}, c.places...),
+		mainCategories:  append([]string{}, c.mainCategories...),
+		subCategories:   append([]string{}, c.subCategories...),
 		employmentTypes: append([]job.EmploymentType{}, c.employmentTypes...),
 		seniorities:     append([]job.Seniority{}, c.seniorities...),
 		remotes:         append([]job.Remote{}, c.remotes...),
 		tags:            append([]string{}, c.tags...),
+		allTags:         append([]string{}, c.allTags...),
+		noTags:          append([]string{}, c.noTags...),
+		salaryMin:       c.salaryMin,
+		salaryMax:       c.salaryMax,
+		salaryCurrency:  append([]string{}, c.salaryCurrency...),
+		maxExperience:   c.maxExperience,
+		search:          c.search,
+		sorts:           append([]Sort{}, c.sorts...),
 	}
 }
 
+// Active keeps the jobs that are still listed.
+func (c Conditions) Active() Conditions {
+	clone := c.Clone()
+	clone.state = activeState
+	return clone
+}
+
+// Closed keeps the jobs that are no longer listed.
+func (c Conditions) Closed() Conditions {
+	clone := c.Clone()
+	clone.state = closedState
+	return clone
+}
+
+// NewSince keeps the jobs first seen at or after t.
+func (c Conditions) NewSince(t time.Time) Conditions {
+	clone := c.Clone()
+	clone.firstSeen.from = t
+	return clone
+}
+
+// FirstSeen keeps the jobs first seen from from until before to. A zero
+// bound is open.
+func (c Conditions) FirstSeen(from, to time.Time) Conditions {
+	clone := c.Clone()
+	clone.firstSeen = timeRange{from: from, to: to}
+	return clone
+}
+
+// FirstSeenWithin narrows the jobs kept to those also first seen from from
+// until before to. A zero bound leaves that side as it is.
+func (c Conditions) FirstSeenWithin(from, to time.Time) Conditions {
+	clone := c.Clone()
+	clone.firstSeen = clone.firstSeen.intersect(timeRange{from: from, to: to})
+	return clone
+}
+
+// Updated keeps the jobs last updated from from until before to. A zero bound
+// is open.
+func (c Conditions) Updated(from, to time.Time) Conditions {
+	clone := c.Clone()
+	clone.updated = timeRange{from: from, to: to}
+	return clone
+}
+
+// Location keeps the jobs whose location contains location.
+func (c Conditions) Location(location string) Conditions {
+	clone := c.Clone()
+	clone.location = location
+	return clone
+}
+
+// Places keeps the jobs located in any of places, including the locations
+// below them in the hierarchy, so a city keeps the jobs in each of its
+// districts.
+func (c Conditions) Places(places ...*location.Location) Conditions {
+	clone := c.Clone()
+	clone.places = append(clone.places, places...)
+	return clone
+}
+
+func (c Conditions) MainCategory(mainCategories ...string) Conditions {
+	clone := c.Clone()
+	clone.mainCategories = append(clone.mainCategories, mainCategories...)
+	return clone
+}
+
+func (c Conditions) SubCategory(subCategories ...string) Conditions {
+	clone := c.Clone()
+	clone.subCategories = append(clone.subCategories, subCategories...)
+	return clone
+}
+
+func (c Conditions) Source(sources ...string) Conditions {
+	clone := c.Clone()
+	clone.sources = append(clone.sources, sources...)
+	return clone
+}
+
 func (c Conditions) Company(company string) Conditions {
 	clone := c.Clone()
 	clone.company = company
 	return clone
 }
 
+// CompanySlug keeps the jobs of the company with the given slug.
+func (c Conditions) CompanySlug(slug string) Conditions {
+	clone := c.Clone()
+	clone.companySlug = slug
+	return clone
+}
+
 func (c Conditions) Title(title string) Conditions {
 	clone := c.Clone()
 	clone.title = title
@@ -60,23 +223,146 @@ func (c Conditions) Remote(remote ...job.Remote) Conditions {
 	return clone
 }
 
+// Tags keeps the jobs with any of tags. Tags are compared by their canonical
+// tag, so "golang" keeps the jobs tagged "Go".
 func (c Conditions) Tags(tags ...string) Conditions {
-	c.tags = append(c.tags, tags...)
-	return c
+	clone := c.Clone()
+	clone.tags = append(clone.tags, util.Map(tags, tag.Canonical)...)
+	return clone
+}
+
+// AllTags keeps the jobs with every one of tags, compared by canonical tag.
+func (c Conditions) AllTags(tags ...string) Conditions {
+	clone := c.Clone()
+	clone.allTags = append(clone.allTags, util.Map(tags, tag.Canonical)...)
+	return clone
+}
+
+// NoTags keeps the jobs with none of tags, compared by canonical tag.
+func (c Conditions) NoTags(tags ...string) Conditions {
+	clone := c.Clone()
+	clone.noTags = append(clone.noTags, util.Map(tags, tag.Canonical)...)
+	return clone
+}
+
+// Salary keeps the jobs whose normalized annual salary is between minAnnual
+// and maxAnnual. A bound of 0 is open. Jobs without a parsed salary are left
+// out.
+func (c Conditions) Salary(minAnnual, maxAnnual float64) Conditions {
+	clone := c.Clone()
+	clone.salaryMin = minAnnual
+	clone.salaryMax = maxAnnual
+	return clone
+}
+
+func (c Conditions) SalaryCurrency(currencies ...string) Conditions {
+	clone := c.Clone()
+	clone.salaryCurrency = append(clone.salaryCurrency, currencies...)
+	return clone
+}
+
+// MaxExperience keeps the jobs that require at most years of experience.
+// Jobs whose requirement could not be parsed are left out.
+func (c Conditions) MaxExperience(years int) Conditions {
+	clone := c.Clone()
+	clone.maxExperience = &years
+	return clone
+}
+
+// Search keeps the jobs whose title, company, description or requirements
+// contain every term of query. "Quoted words" match as a phrase and a
+// trailing * matches by prefix. Jobs found are ranked by relevance. A query
+// without terms keeps every job.
+func (c Conditions) Search(query string) Conditions {
+	clone := c.Clone()
+	clone.search = ""
+	if len(searchTerms(query)) > 0 {
+		clone.search = query
+	}
+	return clone
 }
 
-func (c Conditions) ToSelectBuilder(columns ...string) sq.SelectBuilder {
+// SortBy orders the jobs by sorts, in order of precedence. Searches are
+// otherwise ordered by relevance, and ties are broken by id.
+func (c Conditions) SortBy(sorts ...Sort) Conditions {
+	clone := c.Clone()
+	clone.sorts = append(clone.sorts, sorts...)
+	return clone
+}
+
+// ToSelectBuilder selects columns of the jobs j matching the conditions in
+// the SQL of dialect.
+func (c Conditions) ToSelectBuilder(dialect database.Dialect, columns ...string) sq.SelectBuilder {
 	builder := sq.Select(columns...).
-		From("jobs AS j").
-		Join("jobs_tags AS jt ON j.id = jt.job_id").
-		Join("tags AS t ON jt.tag_id = t.id")
+		From("jobs AS j")
 
+	if c.search != "" {
+		builder = whereSearch(dialect, builder, c.search)
+	}
+
+	switch c.state {
+	case activeState:
+		builder = builder.Where(sq.Eq{"j.closed_at": nil})
+	case closedState:
+		builder = builder.Where(sq.NotEq{"j.closed_at": nil})
+	}
+	builder = c.firstSeen.apply(builder, "j.first_seen_at")
+	builder = c.updated.apply(builder, "j.updated_at")
+	if len(c.sources) > 0 {
+		builder = builder.Where(sq.Eq{"j.source": c.sources})
+	}
 	if c.company != "" {
 		builder = builder.Where(sq.Eq{"j.company": c.company})
 	}
+	if c.companySlug != "" {
+		builder = builder.Where(sq.Expr("j.company_id IN (?)", sq.Select("id").
+			From("companies").
+			Where(sq.Eq{"slug": c.companySlug}),
+		))
+	}
 	if c.title != "" {
 		builder = builder.Where(sq.Eq{"j.title": c.title})
 	}
+	if c.location != "" {
+		// LIKE ignores case in SQLite but not in PostgreSQL.
+		like := "LIKE"
+		if dialect == database.Postgres {
+			like = "ILIKE"
+		}
+		builder = builder.Where("j.location "+like+` ? ESCAPE '\'`, "%"+escapeLike(c.location)+"%")
+	}
+	if len(c.places) > 0 {
+		places := sq.Or{}
+		for _, place := range c.places {
+			eq := sq.Eq{"l.country": place.Country}
+			if place.City != "" {
+				eq["l.city"] = place.City
+			}
+			if place.Area != "" {
+				eq["l.area"] = place.Area
+			}
+			places = append(places, eq)
+		}
+		builder = builder.Where(sq.Expr("EXISTS (?)", sq.Select("1").
+			From("jobs_locations AS jl").
+			Join("locations AS l ON jl.location_id = l.id").
+			Where("jl.job_id = j.id").
+			Where(places),
+		))
+	}
+	if len(c.mainCategories) > 0 || len(c.subCategories) > 0 {
+		categories := sq.Select("1").
+			From("jobs_categories AS jc").
+			Join("categories AS c ON jc.category_id = c.id").
+			Where("jc.job_id = j.id")
+		if len(c.mainCategories) > 0 {
+			categories = categories.Where(sq.Eq{"c.main": c.mainCategories})
+		}
+		if len(c.subCategories) > 0 {
+			categories = categories.Where(sq.Eq{"c.sub": c.subCategories})
+		}
+		builder = builder.Where(sq.Expr("EXISTS (?)", categories))
+	}
 	if len(c.employmentTypes) > 0 {
 		builder = builder.Where(sq.Eq{"j.employment_type": c.employmentTypes})
 	}
@@ -87,7 +373,37 @@ func (c Conditions) ToSelectBuilder(columns ...string) sq.SelectBuilder {
 		builder = builder.Where(sq.Eq{"j.remote": c.remotes})
 	}
 	if len(c.tags) > 0 {
-		builder = builder.Where(sq.Eq{"t.tag": c.tags})
+		builder = builder.Where(sq.Expr("EXISTS (?)", jobTags("1", c.tags)))
+	}
+	if len(c.allTags) > 0 {
+		allTags := slices.Compact(slices.Sorted(slices.Values(c.allTags)))
+		builder = builder.Where(sq.Expr("(?) = ?", jobTags("COUNT(DISTINCT ct.tag)", allTags), len(allTags)))
+	}
+	if len(c.noTags) > 0 {
+		builder = builder.Where(sq.Expr("NOT EXISTS (?)", jobTags("1", c.noTags)))
+	}
+	if c.salaryMin > 0 {
+		builder = builder.Where(sq.GtOrEq{"j.salary_annual": c.salaryMin})
+	}
+	if c.salaryMax > 0 {
+		builder = builder.Where(sq.LtOrEq{"j.salary_annual": c.salaryMax})
+	}
+	if c.maxExperience != nil {
+		builder = builder.Where(sq.LtOrEq{"j.experience_years": *c.maxExperience})
+	}
+	if len(c.salaryCurrency) > 0 {
+		builder = builder.Where(sq.Eq{"j.salary_currency": c.salaryCurrency})
 	}
 	return builder
 }
+
+// jobTags selects column from the tags of the job j whose canonical tag, ct,
+// is among tags.
+func jobTags(column string, tags []string) sq.SelectBuilder {
+	return sq.Select(column).
+		From("jobs_tags AS jt").
+		Join("tags AS t ON jt.tag_id = t.id").
+		Join("tags AS ct ON ct.id = COALESCE(t.canonical_tag_id, t.id)").
+		Where("jt.job_id = j.id").
+		Where(sq.Eq{"ct.tag": tags})
+}
@@ -0,0 +1,199 @@
+package jobrepo
+
+import (
+	"bytes"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// conditionsJSON is how Conditions are serialized. Enums are given by name,
+// as in the jobs, and sorts as ParseSort takes them. Unset conditions are
+// left out.
+type conditionsJSON struct {
+	State           string          `json:"state,omitempty"`
+	FirstSeen       *timeRangeJSON  `json:"first_seen,omitempty"`
+	Updated         *timeRangeJSON  `json:"updated,omitempty"`
+	Sources         []string        `json:"sources,omitempty"`
+	Company         string          `json:"company,omitempty"`
+	CompanySlug     string          `json:"company_slug,omitempty"`
+	Title           string          `json:"title,omitempty"`
+	Location        string          `json:"location,omitempty"`
+	Places          []*locationJSON `json:"places,omitempty"`
+	MainCategories  []string        `json:"main_categories,omitempty"`
+	SubCategories   []string        `json:"sub_categories,omitempty"`
+	EmploymentTypes []string        `json:"employment_types,omitempty"`
+	Seniorities     []string        `json:"seniorities,omitempty"`
+	Remotes         []string        `json:"remotes,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
+	AllTags         []string        `json:"all_tags,omitempty"`
+	NoTags          []string        `json:"no_tags,omitempty"`
+	SalaryMin       float64         `json:"salary_min,omitempty"`
+	SalaryMax       float64         `json:"salary_max,omitempty"`
+	SalaryCurrency  []string        `json:"salary_currency,omitempty"`
+	MaxExperience   *int            `json:"max_experience,omitempty"`
+	Search          string          `json:"search,omitempty"`
+	Sort            []string        `json:"sort,omitempty"`
+}
+
+type timeRangeJSON struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+type locationJSON struct {
+	Country string `json:"country"`
+	City    string `json:"city,omitempty"`
+	Area    string `json:"area,omitempty"`
+}
+
+func newTimeRangeJSON(r timeRange) *timeRangeJSON {
+	if r.from.IsZero() && r.to.IsZero() {
+		return nil
+	}
+	bound := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	return &timeRangeJSON{From: bound(r.from), To: bound(r.to)}
+}
+
+func (r *timeRangeJSON) timeRange() timeRange {
+	if r == nil {
+		return timeRange{}
+	}
+	var tr timeRange
+	if r.From != nil {
+		tr.from = *r.From
+	}
+	if r.To != nil {
+		tr.to = *r.To
+	}
+	return tr
+}
+
+func (c Conditions) MarshalJSON() ([]byte, error) {
+	v := conditionsJSON{
+		FirstSeen:      newTimeRangeJSON(c.firstSeen),
+		Updated:        newTimeRangeJSON(c.updated),
+		Sources:        c.sources,
+		Company:        c.company,
+		CompanySlug:    c.companySlug,
+		Title:          c.title,
+		Location:       c.location,
+		MainCategories: c.mainCategories,
+		SubCategories:  c.subCategories,
+		Tags:           c.tags,
+		AllTags:        c.allTags,
+		NoTags:         c.noTags,
+		SalaryMin:      c.salaryMin,
+		SalaryMax:      c.salaryMax,
+		SalaryCurrency: c.salaryCurrency,
+		MaxExperience:  c.maxExperience,
+		Search:         c.search,
+	}
+	switch c.state {
+	case activeState:
+		v.State = "active"
+	case closedState:
+		v.State = "closed"
+	}
+	for _, place := range c.places {
+		v.Places = append(v.Places, &locationJSON{Country: place.Country, City: place.City, Area: place.Area})
+	}
+	for _, et := range c.employmentTypes {
+		v.EmploymentTypes = append(v.EmploymentTypes, et.String())
+	}
+	for _, s := range c.seniorities {
+		v.Seniorities = append(v.Seniorities, s.String())
+	}
+	for _, r := range c.remotes {
+		v.Remotes = append(v.Remotes, r.String())
+	}
+	for _, sort := range c.sorts {
+		v.Sort = append(v.Sort, sort.String())
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON rejects unknown fields and values rather than dropping them,
+// so that a misspelled condition does not silently keep more jobs.
+func (c *Conditions) UnmarshalJSON(data []byte) error {
+	var v conditionsJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("failed to unmarshal conditions: %w", err)
+	}
+
+	conditions := NewConditions()
+	switch v.State {
+	case "":
+	case "active":
+		conditions = conditions.Active()
+	case "closed":
+		conditions = conditions.Closed()
+	default:
+		return fmt.Errorf("unknown state %q", v.State)
+	}
+	firstSeen, updated := v.FirstSeen.timeRange(), v.Updated.timeRange()
+	conditions = conditions.
+		FirstSeen(firstSeen.from, firstSeen.to).
+		Updated(updated.from, updated.to).
+		Source(v.Sources...).
+		Company(v.Company).
+		CompanySlug(v.CompanySlug).
+		Title(v.Title).
+		Location(v.Location).
+		MainCategory(v.MainCategories...).
+		SubCategory(v.SubCategories...).
+		Tags(v.Tags...).
+		AllTags(v.AllTags...).
+		NoTags(v.NoTags...).
+		Salary(v.SalaryMin, v.SalaryMax).
+		SalaryCurrency(v.SalaryCurrency...).
+		Search(v.Search)
+	if v.MaxExperience != nil {
+		conditions = conditions.MaxExperience(*v.MaxExperience)
+	}
+	for _, place := range v.Places {
+		if place == nil || place.Country == "" {
+			return fmt.Errorf("place without a country")
+		}
+		conditions = conditions.Places(location.NewLocation(place.Country, place.City, place.Area, ""))
+	}
+	for _, name := range v.EmploymentTypes {
+		et := job.NewEmploymentType(name)
+		if et == job.InvalidEmploymentType {
+			return fmt.Errorf("unknown employment type %q", name)
+		}
+		conditions = conditions.EmploymentType(et)
+	}
+	for _, name := range v.Seniorities {
+		s := job.NewSeniority(name)
+		if s == job.InvalidSeniority {
+			return fmt.Errorf("unknown seniority %q", name)
+		}
+		conditions = conditions.Seniority(s)
+	}
+	for _, name := range v.Remotes {
+		r := job.NewRemote(name)
+		if r == job.InvalidRemote {
+			return fmt.Errorf("unknown remote %q", name)
+		}
+		conditions = conditions.Remote(r)
+	}
+	for _, s := range v.Sort {
+		sort, err := ParseSort(s)
+		if err != nil {
+			return err
+		}
+		conditions = conditions.SortBy(sort)
+	}
+	*c = conditions
+	return nil
+}
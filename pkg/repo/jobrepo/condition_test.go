@@ -0,0 +1,160 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionTestSuite struct {
+	suite.Suite
+}
+
+func (s *ConditionTestSuite) TestTags() {
+	tests := []struct {
+		name       string
+		conditions Conditions
+		want       string
+		wantArgs   []any
+	}{
+		{
+			"any of",
+			NewConditions().Tags("golang", "Rust"),
+			"SELECT j.id FROM jobs AS j WHERE EXISTS (SELECT 1 FROM jobs_tags AS jt JOIN tags AS t ON jt.tag_id = t.id JOIN tags AS ct ON ct.id = COALESCE(t.canonical_tag_id, t.id) WHERE jt.job_id = j.id AND ct.tag IN (?,?))",
+			[]any{"Go", "Rust"},
+		},
+		{
+			"all of, ignoring synonyms",
+			NewConditions().AllTags("k8s", "Go", "golang"),
+			"SELECT j.id FROM jobs AS j WHERE (SELECT COUNT(DISTINCT ct.tag) FROM jobs_tags AS jt JOIN tags AS t ON jt.tag_id = t.id JOIN tags AS ct ON ct.id = COALESCE(t.canonical_tag_id, t.id) WHERE jt.job_id = j.id AND ct.tag IN (?,?)) = ?",
+			[]any{"Go", "Kubernetes", 2},
+		},
+		{
+			"none of",
+			NewConditions().NoTags("PHP"),
+			"SELECT j.id FROM jobs AS j WHERE NOT EXISTS (SELECT 1 FROM jobs_tags AS jt JOIN tags AS t ON jt.tag_id = t.id JOIN tags AS ct ON ct.id = COALESCE(t.canonical_tag_id, t.id) WHERE jt.job_id = j.id AND ct.tag IN (?))",
+			[]any{"PHP"},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			sql, args, err := tt.conditions.ToSelectBuilder(database.SQLite, "j.id").ToSql()
+			s.NoError(err)
+			s.Equal(tt.want, sql)
+			s.Equal(tt.wantArgs, args)
+		})
+	}
+}
+
+func (s *ConditionTestSuite) TestLocation() {
+	tests := []struct {
+		dialect database.Dialect
+		want    string
+	}{
+		{database.SQLite, `SELECT j.id FROM jobs AS j WHERE j.location LIKE ? ESCAPE '\'`},
+		{database.Postgres, `SELECT j.id FROM jobs AS j WHERE j.location ILIKE ? ESCAPE '\'`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.dialect.String(), func() {
+			sql, args, err := NewConditions().Location("50%_off").ToSelectBuilder(tt.dialect, "j.id").ToSql()
+			s.NoError(err)
+			s.Equal(tt.want, sql)
+			s.Equal([]any{`%50\%\_off%`}, args)
+		})
+	}
+}
+
+func (s *ConditionTestSuite) TestFirstSeenWithin() {
+	day := func(d int) time.Time { return time.Date(2024, 10, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		conditions Conditions
+		from, to   time.Time
+		want       timeRange
+	}{
+		{"open", NewConditions(), day(5), day(10), timeRange{from: day(5), to: day(10)}},
+		{"wider", NewConditions().FirstSeen(day(7), day(8)), day(5), day(10), timeRange{from: day(7), to: day(8)}},
+		{"narrower", NewConditions().FirstSeen(day(1), day(20)), day(5), day(10), timeRange{from: day(5), to: day(10)}},
+		{"overlapping", NewConditions().FirstSeen(day(1), day(7)), day(5), day(10), timeRange{from: day(5), to: day(7)}},
+		{"open bounds", NewConditions().FirstSeen(day(7), day(8)), time.Time{}, time.Time{}, timeRange{from: day(7), to: day(8)}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.want, tt.conditions.FirstSeenWithin(tt.from, tt.to).firstSeen)
+		})
+	}
+}
+
+func (s *ConditionTestSuite) TestJSON() {
+	// Given
+	since := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	conditions := NewConditions().
+		Active().
+		NewSince(since).
+		Places(location.NewLocation("台灣", "台北市", "", "")).
+		MainCategory("Software").
+		Seniority(job.MidSeniorLevel).
+		Remote(job.FullRemote, job.PartialRemote).
+		Tags("golang").
+		Salary(1_200_000, 0).
+		MaxExperience(5).
+		Search("backend").
+		SortBy(Sort{Key: SortSalary, Desc: true})
+
+	// When
+	data, err := json.Marshal(conditions)
+	s.Require().NoError(err)
+	var unmarshaled Conditions
+	err = json.Unmarshal(data, &unmarshaled)
+
+	// Then
+	s.Require().NoError(err)
+	s.JSONEq(`{
+		"state": "active",
+		"first_seen": {"from": "2024-10-01T00:00:00Z"},
+		"places": [{"country": "台灣", "city": "台北市"}],
+		"main_categories": ["Software"],
+		"seniorities": ["Mid-Senior level"],
+		"remotes": ["100% Remote Work", "Partial Remote Work"],
+		"tags": ["Go"],
+		"salary_min": 1200000,
+		"max_experience": 5,
+		"search": "backend",
+		"sort": ["-salary"]
+	}`, string(data))
+	wantSQL, wantArgs, err := conditions.ToSelectBuilder(database.SQLite, "j.id").ToSql()
+	s.Require().NoError(err)
+	sql, args, err := unmarshaled.ToSelectBuilder(database.SQLite, "j.id").ToSql()
+	s.Require().NoError(err)
+	s.Equal(wantSQL, sql)
+	s.Equal(wantArgs, args)
+	s.Equal(conditions.sorts, unmarshaled.sorts)
+}
+
+func (s *ConditionTestSuite) TestJSON_Invalid() {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown field", `{"remote": ["100% Remote Work"]}`},
+		{"unknown state", `{"state": "open"}`},
+		{"unknown remote", `{"remotes": ["Remote"]}`},
+		{"unknown sort", `{"sort": ["-posted_at"]}`},
+		{"place without a country", `{"places": [{"city": "台北市"}]}`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			var conditions Conditions
+			s.Error(json.Unmarshal([]byte(tt.data), &conditions))
+		})
+	}
+}
+
+func TestConditionTestSuite(t *testing.T) {
+	suite.Run(t, new(ConditionTestSuite))
+}
@@ -0,0 +1,743 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/tag"
+	"cake-scraper/pkg/util"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var _ JobRepo = (*memoryJobRepo)(nil)
+
+// memoryJob is a stored job with the columns the database keeps beside it.
+type memoryJob struct {
+	job       *job.Job
+	fetchedAt time.Time
+	location  *location.Location
+}
+
+// memoryJobRepo keeps jobs in memory. It honors Conditions, sorting and
+// pagination like the database repos, so that it can stand in for them in
+// tests and in runs that need not persist anything.
+type memoryJobRepo struct {
+	mu        sync.RWMutex
+	jobs      []*memoryJob
+	nextID    int64
+	tagIDs    map[string]int64
+	revisions []*job.Revision
+	// listings are the ids of the jobs each listing lists, and runs the ids
+	// of the scrape runs each job was seen by.
+	listings map[string]map[int64]bool
+	runs     map[int64]map[int64]bool
+}
+
+func NewMemoryJobRepo() *memoryJobRepo {
+	return &memoryJobRepo{
+		tagIDs:   map[string]int64{},
+		listings: map[string]map[int64]bool{},
+		runs:     map[int64]map[int64]bool{},
+	}
+}
+
+// memoryNow returns the current time at the precision the database stores.
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func cloneJob(j *job.Job) *job.Job {
+	clone := *j
+	clone.Tags = append([]string{}, j.Tags...)
+	if j.ExperienceYears != nil {
+		years := *j.ExperienceYears
+		clone.ExperienceYears = &years
+	}
+	if j.SalaryRange != nil {
+		salaryRange := *j.SalaryRange
+		clone.SalaryRange = &salaryRange
+	}
+	return &clone
+}
+
+// memoryColumns are the columns Find and Delete can match by.
+var memoryColumns = map[string]func(j *job.Job) any{
+	"id":       func(j *job.Job) any { return j.ID },
+	"source":   func(j *job.Job) any { return j.Source },
+	"link":     func(j *job.Job) any { return j.Link },
+	"company":  func(j *job.Job) any { return j.Company },
+	"title":    func(j *job.Job) any { return j.Title },
+	"location": func(j *job.Job) any { return j.Location },
+}
+
+// where returns the stored jobs whose columns equal the conditions, or are
+// among them if a condition is a slice. r.mu must be held.
+func (r *memoryJobRepo) where(conditions map[string]interface{}) ([]*memoryJob, error) {
+	for column := range conditions {
+		if memoryColumns[column] == nil {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return util.Filter(r.jobs, func(stored *memoryJob) bool {
+		for column, want := range conditions {
+			if !util.MatchValue(memoryColumns[column](stored.job), want) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+func (r *memoryJobRepo) Find(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	matched, err := r.where(conditions)
+	if err != nil {
+		return nil, err
+	}
+	return util.Map(matched, func(stored *memoryJob) *job.Job {
+		return cloneJob(stored.job)
+	}), nil
+}
+
+func (r *memoryJobRepo) FindPaginated(ctx context.Context, conditions Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	terms := memorySearchTerms(conditions.search)
+	ranks := map[int64]float64{}
+	jobs := []*job.Job{}
+	for _, stored := range r.jobs {
+		if !conditions.match(stored) {
+			continue
+		}
+		j := cloneJob(stored.job)
+		if conditions.search != "" {
+			var matched bool
+			ranks[j.ID], j.Snippet, matched = searchJob(j, terms)
+			if !matched {
+				continue
+			}
+		}
+		jobs = append(jobs, j)
+	}
+	slices.SortStableFunc(jobs, func(a, b *job.Job) int {
+		return conditions.compare(a, b, ranks)
+	})
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*job.Job, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return jobs[offset : offset+limit], nil
+	}, page, perPage, int64(len(jobs))), nil
+}
+
+func (r *memoryJobRepo) FindByID(ctx context.Context, id int64) (*job.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if stored := r.byID(id); stored != nil {
+		return cloneJob(stored.job), nil
+	}
+	return nil, nil
+}
+
+func (r *memoryJobRepo) FindRevisions(ctx context.Context, jobID int64) ([]*job.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := []*job.Revision{}
+	for _, revision := range r.revisions {
+		if revision.JobID == jobID {
+			clone := *revision
+			revisions = append(revisions, &clone)
+		}
+	}
+	return revisions, nil
+}
+
+func (r *memoryJobRepo) CountTags(ctx context.Context, conditions Conditions, limit int64) ([]*job.TagCount, error) {
+	paginator, err := r.FindPaginated(ctx, conditions, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := paginator.Slice(0, paginator.Total())
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, j := range jobs {
+		counted := map[string]bool{}
+		for _, t := range j.Tags {
+			canonical := tag.Canonical(t)
+			if counted[canonical] || slices.Contains(conditions.allTags, canonical) {
+				continue
+			}
+			counted[canonical] = true
+			counts[canonical]++
+		}
+	}
+	tagCounts := []*job.TagCount{}
+	for t, n := range counts {
+		tagCounts = append(tagCounts, &job.TagCount{Tag: t, Jobs: n})
+	}
+	slices.SortFunc(tagCounts, func(a, b *job.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Jobs, a.Jobs), strings.Compare(a.Tag, b.Tag))
+	})
+	return tagCounts[:min(int64(len(tagCounts)), limit)], nil
+}
+
+// byID returns the stored job with the given id, or nil. r.mu must be held.
+func (r *memoryJobRepo) byID(id int64) *memoryJob {
+	for _, stored := range r.jobs {
+		if stored.job.ID == id {
+			return stored
+		}
+	}
+	return nil
+}
+
+// byLink returns the stored job with the given link, or nil. r.mu must be
+// held.
+func (r *memoryJobRepo) byLink(link string) *memoryJob {
+	for _, stored := range r.jobs {
+		if stored.job.Link == link {
+			return stored
+		}
+	}
+	return nil
+}
+
+// tags returns the distinct tags in the order the database returns them, by
+// when each tag was first saved. Like the tags table, a tag is saved after its
+// canonical tag. r.mu must be held.
+func (r *memoryJobRepo) tags(tags []string) []string {
+	for _, t := range tags {
+		for _, name := range []string{tag.Canonical(t), t} {
+			if _, ok := r.tagIDs[name]; !ok {
+				r.tagIDs[name] = int64(len(r.tagIDs) + 1)
+			}
+		}
+	}
+	sorted := util.Unique(tags)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return cmp.Compare(r.tagIDs[a], r.tagIDs[b])
+	})
+	return sorted
+}
+
+// linkRun records that the scrape run runID saw the job, unless runID is 0.
+// r.mu must be held.
+func (r *memoryJobRepo) linkRun(jobID, runID int64) {
+	if runID == 0 {
+		return
+	}
+	if r.runs[jobID] == nil {
+		r.runs[jobID] = map[int64]bool{}
+	}
+	r.runs[jobID][runID] = true
+}
+
+// RunLinks returns the links of the jobs the scrape run runID saw, by id. A
+// memory run repo finds the links of its runs with it.
+func (r *memoryJobRepo) RunLinks(runID int64) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	links := []string{}
+	for _, stored := range r.jobs {
+		if r.runs[stored.job.ID][runID] {
+			links = append(links, stored.job.Link)
+		}
+	}
+	return links
+}
+
+func (r *memoryJobRepo) Save(ctx context.Context, j *job.Job, runID int64) (SaveResult, error) {
+	if err := ctx.Err(); err != nil {
+		return Created, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.save(j, runID), nil
+}
+
+func (r *memoryJobRepo) SaveAll(ctx context.Context, jobs []*job.Job, runID int64) ([]SaveResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return util.Map(jobs, func(j *job.Job) SaveResult {
+		return r.save(j, runID)
+	}), nil
+}
+
+// save upserts the job. r.mu must be held.
+func (r *memoryJobRepo) save(j *job.Job, runID int64) SaveResult {
+	now := memoryNow()
+	stored := r.byLink(j.Link)
+	if stored != nil {
+		changes := job.Diff(stored.job, j)
+		if len(changes) == 0 {
+			// Parsed fields are refreshed even when the job is unchanged.
+			parsed := cloneJob(j)
+			stored.job.ExperienceYears, stored.job.SalaryRange = parsed.ExperienceYears, parsed.SalaryRange
+			stored.job.LastSeenAt, stored.job.ClosedAt = now, time.Time{}
+			stored.fetchedAt = now
+			r.linkRun(stored.job.ID, runID)
+			return Unchanged
+		}
+		updated := cloneJob(j)
+		updated.ID, updated.FirstSeenAt = stored.job.ID, stored.job.FirstSeenAt
+		updated.LastSeenAt, updated.UpdatedAt, updated.ChangedAt, updated.ClosedAt = now, now, now, time.Time{}
+		updated.Tags, updated.Snippet = r.tags(j.Tags), ""
+		stored.job, stored.fetchedAt, stored.location = updated, now, location.FindBestMatch(j.Location)
+		r.revisions = append(r.revisions, &job.Revision{
+			ID:        int64(len(r.revisions) + 1),
+			JobID:     updated.ID,
+			RunID:     runID,
+			ChangedAt: now,
+			Changes:   changes,
+		})
+		r.linkRun(updated.ID, runID)
+		return Updated
+	}
+	r.nextID++
+	created := cloneJob(j)
+	created.ID = r.nextID
+	created.FirstSeenAt, created.LastSeenAt, created.UpdatedAt = now, now, now
+	created.ClosedAt, created.ChangedAt = time.Time{}, time.Time{}
+	created.Tags, created.Snippet = r.tags(j.Tags), ""
+	r.jobs = append(r.jobs, &memoryJob{job: created, fetchedAt: now, location: location.FindBestMatch(j.Location)})
+	r.linkRun(created.ID, runID)
+	return Created
+}
+
+func (r *memoryJobRepo) LastFetched(ctx context.Context, links []string) (map[string]time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lastFetched := map[string]time.Time{}
+	for _, link := range links {
+		if stored := r.byLink(link); stored != nil {
+			lastFetched[link] = stored.fetchedAt
+		}
+	}
+	return lastFetched, nil
+}
+
+func (r *memoryJobRepo) MarkSeen(ctx context.Context, links []string, runID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := memoryNow()
+	for _, link := range links {
+		if stored := r.byLink(link); stored != nil {
+			stored.job.LastSeenAt, stored.job.ClosedAt = now, time.Time{}
+			r.linkRun(stored.job.ID, runID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryJobRepo) Close(ctx context.Context, links []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := memoryNow()
+	closed := 0
+	for _, link := range links {
+		if stored := r.byLink(link); stored != nil && !stored.job.Closed() {
+			stored.job.ClosedAt = now
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+func (r *memoryJobRepo) CloseUnlisted(ctx context.Context, source, listing string, links []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listed := map[int64]bool{}
+	for _, link := range links {
+		if stored := r.byLink(link); stored != nil && stored.job.Source == source {
+			listed[stored.job.ID] = true
+		}
+	}
+	if r.listings[listing] == nil {
+		r.listings[listing] = map[int64]bool{}
+	}
+	now := memoryNow()
+	closed := 0
+	for id := range r.listings[listing] {
+		stored := r.byID(id)
+		if listed[id] || stored == nil || stored.job.Source != source || stored.job.Closed() {
+			continue
+		}
+		stored.job.ClosedAt = now
+		delete(r.listings[listing], id)
+		closed++
+	}
+	for id := range listed {
+		r.listings[listing][id] = true
+	}
+	return closed, nil
+}
+
+func (r *memoryJobRepo) Delete(ctx context.Context, conditions map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched, err := r.where(conditions)
+	if err != nil {
+		return err
+	}
+	r.jobs = util.Filter(r.jobs, func(stored *memoryJob) bool {
+		return !slices.Contains(matched, stored)
+	})
+	for _, stored := range matched {
+		delete(r.runs, stored.job.ID)
+		for _, ids := range r.listings {
+			delete(ids, stored.job.ID)
+		}
+		r.revisions = util.Filter(r.revisions, func(revision *job.Revision) bool {
+			return revision.JobID != stored.job.ID
+		})
+	}
+	return nil
+}
+
+// match reports whether the stored job meets the conditions other than the
+// search, like the WHERE clause of ToSelectBuilder.
+func (c Conditions) match(stored *memoryJob) bool {
+	j := stored.job
+	switch c.state {
+	case activeState:
+		if j.Closed() {
+			return false
+		}
+	case closedState:
+		if !j.Closed() {
+			return false
+		}
+	}
+	if !c.firstSeen.contains(j.FirstSeenAt) || !c.updated.contains(j.UpdatedAt) {
+		return false
+	}
+	if len(c.sources) > 0 && !slices.Contains(c.sources, j.Source) {
+		return false
+	}
+	if c.company != "" && j.Company != c.company {
+		return false
+	}
+	if c.companySlug != "" && j.CompanySlug != c.companySlug {
+		return false
+	}
+	if c.title != "" && j.Title != c.title {
+		return false
+	}
+	if c.location != "" && !strings.Contains(strings.ToLower(j.Location), strings.ToLower(c.location)) {
+		return false
+	}
+	if len(c.places) > 0 && !slices.ContainsFunc(c.places, func(place *location.Location) bool {
+		l := stored.location
+		return l != nil && l.Country == place.Country &&
+			(place.City == "" || l.City == place.City) &&
+			(place.Area == "" || l.Area == place.Area)
+	}) {
+		return false
+	}
+	if len(c.mainCategories) > 0 && !slices.Contains(c.mainCategories, j.MainCategory) {
+		return false
+	}
+	if len(c.subCategories) > 0 && !slices.Contains(c.subCategories, j.SubCategory) {
+		return false
+	}
+	if len(c.employmentTypes) > 0 && !slices.Contains(c.employmentTypes, j.EmploymentType) {
+		return false
+	}
+	if len(c.seniorities) > 0 && !slices.Contains(c.seniorities, j.Seniority) {
+		return false
+	}
+	if len(c.remotes) > 0 && !slices.Contains(c.remotes, j.Remote) {
+		return false
+	}
+	tags := util.Map(j.Tags, tag.Canonical)
+	hasTag := func(t string) bool { return slices.Contains(tags, t) }
+	if len(c.tags) > 0 && !slices.ContainsFunc(c.tags, hasTag) {
+		return false
+	}
+	for _, t := range c.allTags {
+		if !hasTag(t) {
+			return false
+		}
+	}
+	if slices.ContainsFunc(c.noTags, hasTag) {
+		return false
+	}
+	if c.salaryMin > 0 && (j.SalaryRange == nil || j.SalaryRange.Annual() < c.salaryMin) {
+		return false
+	}
+	if c.salaryMax > 0 && (j.SalaryRange == nil || j.SalaryRange.Annual() > c.salaryMax) {
+		return false
+	}
+	if c.maxExperience != nil && (j.ExperienceYears == nil || *j.ExperienceYears > *c.maxExperience) {
+		return false
+	}
+	if len(c.salaryCurrency) > 0 {
+		currency := ""
+		if j.SalaryRange != nil {
+			currency = j.SalaryRange.Currency
+		}
+		if !slices.Contains(c.salaryCurrency, currency) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r timeRange) contains(t time.Time) bool {
+	return (r.from.IsZero() || !t.Before(r.from)) && (r.to.IsZero() || t.Before(r.to))
+}
+
+// compare orders jobs like orderBy: by the sorts with missing values last,
+// then by rank when searching, then by id.
+func (c Conditions) compare(a, b *job.Job, ranks map[int64]float64) int {
+	for _, sort := range c.sorts {
+		va, vb := sortValue(a, sort.Key), sortValue(b, sort.Key)
+		switch {
+		case va == nil && vb == nil:
+			continue
+		case va == nil:
+			return 1
+		case vb == nil:
+			return -1
+		}
+		n := compareValues(va, vb)
+		if sort.Desc {
+			n = -n
+		}
+		if n != 0 {
+			return n
+		}
+	}
+	if c.search != "" {
+		if n := cmp.Compare(ranks[b.ID], ranks[a.ID]); n != 0 {
+			return n
+		}
+	}
+	n := cmp.Compare(a.ID, b.ID)
+	if len(c.sorts) > 0 && c.sorts[len(c.sorts)-1].Desc {
+		n = -n
+	}
+	return n
+}
+
+// sortValue returns the value of j that key sorts by, or nil if it is
+// missing.
+func sortValue(j *job.Job, key SortKey) any {
+	switch key {
+	case SortFirstSeen:
+		return j.FirstSeenAt
+	case SortUpdated:
+		return j.UpdatedAt
+	case SortCompany:
+		return strings.ToLower(j.Company)
+	case SortTitle:
+		return strings.ToLower(j.Title)
+	case SortSalary:
+		if j.SalaryRange == nil {
+			return nil
+		}
+		return j.SalaryRange.Annual()
+	case SortNumberToHire:
+		return float64(j.NumberToHire)
+	default:
+		return nil
+	}
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(a, b.(string))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	default:
+		return 0
+	}
+}
+
+// searchWeights weigh the matches in the title, company, description and
+// requirements of a job, like searchRank.
+var searchWeights = []float64{10, 5, 1, 1}
+
+// textToken is a word of a text and where it is in the text.
+type textToken struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into lowercase words of letters and digits, like the
+// full-text tokenizers.
+func tokenize(text string) []textToken {
+	tokens := []textToken{}
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			tokens = append(tokens, textToken{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, textToken{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// memorySearchTerm is a search term split into words. The last word matches
+// by prefix if the term does.
+type memorySearchTerm struct {
+	words  []string
+	prefix bool
+}
+
+func memorySearchTerms(search string) []memorySearchTerm {
+	terms := []memorySearchTerm{}
+	for _, term := range searchTerms(search) {
+		words := util.Map(tokenize(term.text), func(t textToken) string { return t.word })
+		if len(words) > 0 {
+			terms = append(terms, memorySearchTerm{words: words, prefix: term.prefix})
+		}
+	}
+	return terms
+}
+
+// matches returns the index of the first token of each match of the term in
+// tokens.
+func (t memorySearchTerm) matches(tokens []textToken) []int {
+	matches := []int{}
+	for i := 0; i+len(t.words) <= len(tokens); i++ {
+		matched := true
+		for k, word := range t.words {
+			last := k == len(t.words)-1
+			if tokens[i+k].word != word && !(last && t.prefix && strings.HasPrefix(tokens[i+k].word, word)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// searchJob reports whether j contains every term, with its rank, higher
+// being more relevant, and a snippet of the column matching best.
+func searchJob(j *job.Job, terms []memorySearchTerm) (float64, string, bool) {
+	if len(terms) == 0 {
+		return 0, "", true
+	}
+	columns := []string{j.Title, j.Company, j.JobDescription, j.Requirements}
+	tokens := util.Map(columns, tokenize)
+	rank := 0.0
+	bestColumn, bestScore := 0, 0.0
+	highlights := make([]map[int]int, len(columns))
+	for _, term := range terms {
+		found := false
+		for i := range columns {
+			matches := term.matches(tokens[i])
+			if len(matches) == 0 {
+				continue
+			}
+			found = true
+			score := searchWeights[i] * float64(len(matches))
+			rank += score
+			if highlights[i] == nil {
+				highlights[i] = map[int]int{}
+			}
+			for _, start := range matches {
+				highlights[i][start] = max(highlights[i][start], len(term.words))
+			}
+			if score > bestScore {
+				bestColumn, bestScore = i, score
+			}
+		}
+		if !found {
+			return 0, "", false
+		}
+	}
+	return rank, snippet(columns[bestColumn], tokens[bestColumn], highlights[bestColumn]), true
+}
+
+// snippetTokens is the number of words of a snippet.
+const snippetTokens = 16
+
+// snippet returns an excerpt of text around its first highlight, with the
+// highlighted words enclosed in job.HighlightStart and job.HighlightEnd.
+// highlights maps the index of the first token of each highlight to its
+// length.
+func snippet(text string, tokens []textToken, highlights map[int]int) string {
+	first := len(tokens)
+	for start := range highlights {
+		first = min(first, start)
+	}
+	from := max(min(first-2, len(tokens)-snippetTokens), 0)
+	to := min(from+snippetTokens, len(tokens))
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("…")
+		pos = tokens[from].start
+	}
+	for i := from; i < to; i++ {
+		n, ok := highlights[i]
+		if !ok {
+			continue
+		}
+		end := tokens[min(i+n, to)-1].end
+		b.WriteString(text[pos:tokens[i].start])
+		b.WriteString(job.HighlightStart + text[tokens[i].start:end] + job.HighlightEnd)
+		pos = end
+		i += n - 1
+	}
+	if to < len(tokens) {
+		b.WriteString(text[pos:tokens[to-1].end])
+		b.WriteString("…")
+	} else {
+		b.WriteString(text[pos:])
+	}
+	return b.String()
+}
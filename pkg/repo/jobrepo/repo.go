@@ -4,67 +4,160 @@ import (
 	"cake-scraper/pkg/database"
 	"cake-scraper/pkg/job"
 	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/repo/companyrepo"
+	"cake-scraper/pkg/repo/tagrepo"
 	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"maps"
+	"slices"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 )
 
 var _ JobRepo = (*jobRepoImpl)(nil)
 
-type Time time.Time
+// maxChunkSize bounds the number of values bound in a single IN clause.
+const maxChunkSize = 500
+
+type Time = database.Time
+
+// SaveResult tells how Save changed the stored job.
+type SaveResult int
+
+const (
+	Created SaveResult = iota
+	Updated
+	Unchanged
+)
 
 type JobPo struct {
-	ID               int64  `db:"id"`
-	Link             string `db:"link"`
-	Company          string `db:"company"`
-	Title            string `db:"title"`
-	EmploymentType   int64  `db:"employment_type"`
-	Seniority        int64  `db:"seniority"`
-	Location         string `db:"location"`
-	NumberToHire     int64  `db:"number_to_hire"`
-	Experience       string `db:"experience"`
-	Salary           string `db:"salary"`
-	Remote           int64  `db:"remote"`
-	InterviewProcess string `db:"interview_process"`
-	JobDescription   string `db:"job_description"`
-	Requirements     string `db:"requirements"`
-	CreatedAt        Time   `db:"created_at"`
-	UpdatedAt        Time   `db:"updated_at"`
+	ID               int64    `db:"id"`
+	Source           string   `db:"source"`
+	Link             string   `db:"link"`
+	Company          string   `db:"company"`
+	CompanyID        *int64   `db:"company_id"`
+	Title            string   `db:"title"`
+	EmploymentType   int64    `db:"employment_type"`
+	Seniority        int64    `db:"seniority"`
+	Location         string   `db:"location"`
+	NumberToHire     int64    `db:"number_to_hire"`
+	Experience       string   `db:"experience"`
+	ExperienceYears  *int64   `db:"experience_years"`
+	Salary           string   `db:"salary"`
+	SalaryMin        *float64 `db:"salary_min"`
+	SalaryMax        *float64 `db:"salary_max"`
+	SalaryCurrency   string   `db:"salary_currency"`
+	SalaryPeriod     int64    `db:"salary_period"`
+	SalaryAnnual     *float64 `db:"salary_annual"`
+	Remote           int64    `db:"remote"`
+	InterviewProcess string   `db:"interview_process"`
+	JobDescription   string   `db:"job_description"`
+	Requirements     string   `db:"requirements"`
+	CreatedAt        Time     `db:"created_at"`
+	UpdatedAt        Time     `db:"updated_at"`
+	FetchedAt        Time     `db:"fetched_at"`
+	FirstSeenAt      Time     `db:"first_seen_at"`
+	LastSeenAt       Time     `db:"last_seen_at"`
+	ClosedAt         Time     `db:"closed_at"`
+	ChangedAt        Time     `db:"changed_at"`
+	Snippet          string   `db:"snippet"`
 }
 
-type JobRepo interface {
-	Find(conditions map[string]interface{}) ([]*job.Job, error)
-	FindPaginated(conditions Conditions, page, perPage int64) util.Paginator[*job.Job]
-	Save(j *job.Job) error
-	Delete(conditions map[string]interface{}) error
+type RevisionPo struct {
+	ID        int64  `db:"id"`
+	JobID     int64  `db:"job_id"`
+	RunID     *int64 `db:"run_id"`
+	ChangedAt Time   `db:"changed_at"`
+	Changes   string `db:"changes"`
 }
 
-type jobRepoImpl struct {
-	db *database.DB
+type JobTagPo struct {
+	JobID int64  `db:"job_id"`
+	Tag   string `db:"tag"`
 }
 
-func (t Time) Value() (time.Time, error) {
-	return time.Time(t), nil
+type JobCategoryPo struct {
+	JobID int64  `db:"job_id"`
+	Main  string `db:"main"`
+	Sub   string `db:"sub"`
 }
 
-func (t *Time) Scan(v interface{}) error {
-	if v == nil {
-		*t = Time(time.Time{})
-		return nil
-	}
-	tp, err := time.Parse(time.DateTime, v.(string))
-	if err != nil {
-		return err
-	}
-	*t = Time(tp)
-	return nil
+type JobCompanyPo struct {
+	ID   int64  `db:"id"`
+	Slug string `db:"slug"`
+}
+
+type TagCountPo struct {
+	Tag  string `db:"tag"`
+	Jobs int64  `db:"jobs"`
+}
+
+type JobRepo interface {
+	Find(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error)
+	// FindPaginated counts the jobs matching conditions and returns the given
+	// page of them, fetched on demand.
+	FindPaginated(ctx context.Context, conditions Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error)
+	// FindByID returns the job with the given id, or nil if there is none.
+	FindByID(ctx context.Context, id int64) (*job.Job, error)
+	// FindRevisions returns the changes seen to the job with the given id,
+	// oldest first.
+	FindRevisions(ctx context.Context, jobID int64) ([]*job.Revision, error)
+	// CountTags returns the limit most frequent canonical tags of the jobs
+	// matching conditions, most frequent first. The tags the jobs must all
+	// have are left out, so the rest are the tags co-occurring with them.
+	CountTags(ctx context.Context, conditions Conditions, limit int64) ([]*job.TagCount, error)
+	// Save upserts the job by link and links it to the scrape run runID,
+	// unless runID is 0. Changes to a stored job are recorded as a revision.
+	Save(ctx context.Context, j *job.Job, runID int64) (SaveResult, error)
+	// SaveAll saves the jobs like Save in a single transaction, so either all
+	// of them are saved or none are. It returns the result of each job.
+	SaveAll(ctx context.Context, jobs []*job.Job, runID int64) ([]SaveResult, error)
+	// LastFetched returns when each of the known links was last saved.
+	// Unknown links are left out.
+	LastFetched(ctx context.Context, links []string) (map[string]time.Time, error)
+	// MarkSeen marks the jobs with the given links as still listed and links
+	// them to the scrape run runID without fetching them again.
+	MarkSeen(ctx context.Context, links []string, runID int64) error
+	// Close marks the jobs with the given links as closed and returns how
+	// many of them were open.
+	Close(ctx context.Context, links []string) (int, error)
+	// CloseUnlisted records that listing, e.g. a profession of source, lists
+	// exactly the given links, and closes the open jobs of source it listed
+	// before but no longer does. It returns how many jobs were closed.
+	CloseUnlisted(ctx context.Context, source, listing string, links []string) (int, error)
+	Delete(ctx context.Context, conditions map[string]interface{}) error
+}
+
+type jobRepoImpl struct {
+	db *database.DB
 }
 
 func (j *JobPo) ToJob() *job.Job {
+	var experienceYears *int
+	if j.ExperienceYears != nil {
+		years := int(*j.ExperienceYears)
+		experienceYears = &years
+	}
+	var salaryRange *job.SalaryRange
+	if j.SalaryAnnual != nil {
+		salaryRange = &job.SalaryRange{
+			Currency: j.SalaryCurrency,
+			Period:   job.SalaryPeriod(j.SalaryPeriod),
+		}
+		if j.SalaryMin != nil {
+			salaryRange.Min = *j.SalaryMin
+		}
+		if j.SalaryMax != nil {
+			salaryRange.Max = *j.SalaryMax
+		}
+	}
 	return &job.Job{
+		ID:               j.ID,
+		Source:           j.Source,
 		Company:          j.Company,
 		Title:            j.Title,
 		Link:             j.Link,
@@ -73,21 +166,49 @@ func (j *JobPo) ToJob() *job.Job {
 		Location:         j.Location,
 		NumberToHire:     int(j.NumberToHire),
 		Experience:       j.Experience,
+		ExperienceYears:  experienceYears,
 		Salary:           j.Salary,
+		SalaryRange:      salaryRange,
 		Remote:           job.Remote(j.Remote),
 		InterviewProcess: j.InterviewProcess,
 		JobDescription:   j.JobDescription,
 		Requirements:     j.Requirements,
+		FirstSeenAt:      time.Time(j.FirstSeenAt),
+		LastSeenAt:       time.Time(j.LastSeenAt),
+		UpdatedAt:        time.Time(j.UpdatedAt),
+		ClosedAt:         time.Time(j.ClosedAt),
+		ChangedAt:        time.Time(j.ChangedAt),
+		Snippet:          j.Snippet,
 	}
 }
 
+func (r *RevisionPo) ToRevision() (*job.Revision, error) {
+	revision := &job.Revision{
+		ID:        r.ID,
+		JobID:     r.JobID,
+		ChangedAt: time.Time(r.ChangedAt),
+	}
+	if r.RunID != nil {
+		revision.RunID = *r.RunID
+	}
+	if err := json.Unmarshal([]byte(r.Changes), &revision.Changes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal changes: %w", err)
+	}
+	return revision, nil
+}
+
 func NewJobRepo() *jobRepoImpl {
 	db, err := database.Connect()
 	util.PanicError(err)
 	return &jobRepoImpl{db: db}
 }
 
-func (r *jobRepoImpl) Find(conditions map[string]interface{}) ([]*job.Job, error) {
+// NewJobRepoWithDB returns a repo over db instead of the shared database.
+func NewJobRepoWithDB(db *database.DB) *jobRepoImpl {
+	return &jobRepoImpl{db: db}
+}
+
+func (r *jobRepoImpl) Find(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error) {
 	if conditions == nil {
 		conditions = map[string]interface{}{}
 	}
@@ -99,102 +220,288 @@ func (r *jobRepoImpl) Find(conditions map[string]interface{}) ([]*job.Job, error
 		return nil, err
 	}
 	var jobPos []*JobPo
-	err = r.db.Select(&jobPos, sql, args...)
+	err = r.db.SelectContext(ctx, &jobPos, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select jobs: %w", err)
 	}
-	var result []*job.Job
+	return toJobs(ctx, r.db, jobPos)
+}
+
+// toJobs converts the job rows to jobs, loading their tags, categories and
+// companies in a constant number of queries rather than per job.
+func toJobs(ctx context.Context, q sqlx.QueryerContext, jobPos []*JobPo) ([]*job.Job, error) {
+	jobs := make([]*job.Job, 0, len(jobPos))
+	byID := make(map[int64]*job.Job, len(jobPos))
+	byCompanyID := map[int64][]*job.Job{}
 	for _, jobPo := range jobPos {
 		j := jobPo.ToJob()
-		tags, err := r.findTags(jobPo.ID)
+		j.Tags = []string{}
+		jobs = append(jobs, j)
+		byID[j.ID] = j
+		if jobPo.CompanyID != nil {
+			byCompanyID[*jobPo.CompanyID] = append(byCompanyID[*jobPo.CompanyID], j)
+		}
+	}
+	for _, chunk := range util.Chunk(slices.Collect(maps.Keys(byCompanyID)), maxChunkSize) {
+		sql, args, err := sq.Select("id", "slug").
+			From("companies").
+			Where(sq.Eq{"id": chunk}).
+			ToSql()
 		if err != nil {
 			return nil, err
 		}
-		j.Tags = tags
-		mainCategory, subCategory, err := r.findCategories(jobPo.ID)
+		var companies []*JobCompanyPo
+		if err := sqlx.SelectContext(ctx, q, &companies, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select companies: %w", err)
+		}
+		for _, company := range companies {
+			for _, j := range byCompanyID[company.ID] {
+				j.CompanySlug = company.Slug
+			}
+		}
+	}
+	for _, chunk := range util.Chunk(slices.Collect(maps.Keys(byID)), maxChunkSize) {
+		sql, args, err := sq.Select("jt.job_id", "t.tag").
+			From("jobs_tags AS jt").
+			Join("tags AS t ON jt.tag_id = t.id").
+			Where(sq.Eq{"jt.job_id": chunk}).
+			OrderBy("jt.job_id", "jt.tag_id").
+			ToSql()
 		if err != nil {
 			return nil, err
 		}
-		j.MainCategory = mainCategory
-		j.SubCategory = subCategory
-		result = append(result, j)
+		var tags []*JobTagPo
+		if err := sqlx.SelectContext(ctx, q, &tags, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select tags: %w", err)
+		}
+		for _, tag := range tags {
+			byID[tag.JobID].Tags = append(byID[tag.JobID].Tags, tag.Tag)
+		}
+		sql, args, err = sq.Select("jc.job_id", "c.main", "c.sub").
+			From("jobs_categories AS jc").
+			Join("categories AS c ON jc.category_id = c.id").
+			Where(sq.Eq{"jc.job_id": chunk}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		var categories []*JobCategoryPo
+		if err := sqlx.SelectContext(ctx, q, &categories, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select categories: %w", err)
+		}
+		for _, category := range categories {
+			byID[category.JobID].MainCategory = category.Main
+			byID[category.JobID].SubCategory = category.Sub
+		}
 	}
-	return result, nil
+	return jobs, nil
 }
 
-func (r *jobRepoImpl) findTags(jobID int64) ([]string, error) {
-	sql, args, err := sq.Select("tag").
-		From("jobs_tags").
-		Join("tags ON jobs_tags.tag_id = tags.id").
-		Where(sq.Eq{"job_id": jobID}).
+func (r *jobRepoImpl) FindPaginated(ctx context.Context, conditions Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error) {
+	var total int64
+	sql, args, err := conditions.ToSelectBuilder(r.db.Dialect, "COUNT(*)").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.GetContext(ctx, &total, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*job.Job, error) {
+		var jobPos []*JobPo
+		builder := conditions.orderBy(r.db.Dialect, conditions.ToSelectBuilder(r.db.Dialect, "j.*"))
+		if conditions.search != "" {
+			builder = selectSnippet(r.db.Dialect, builder, conditions.search)
+		}
+		sql, args, err := builder.Offset(uint64(offset)).Limit(uint64(limit)).ToSql()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.db.SelectContext(ctx, &jobPos, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select jobs: %w", err)
+		}
+		return toJobs(ctx, r.db, jobPos)
+	}, page, perPage, total), nil
+}
+
+func (r *jobRepoImpl) CountTags(ctx context.Context, conditions Conditions, limit int64) ([]*job.TagCount, error) {
+	builder := sq.Select("ct.tag", "COUNT(DISTINCT jt.job_id) AS jobs").
+		From("jobs_tags AS jt").
+		Join("tags AS t ON jt.tag_id = t.id").
+		Join("tags AS ct ON ct.id = COALESCE(t.canonical_tag_id, t.id)").
+		Where(sq.Expr("jt.job_id IN (?)", conditions.ToSelectBuilder(r.db.Dialect, "j.id")))
+	if len(conditions.allTags) > 0 {
+		builder = builder.Where(sq.NotEq{"ct.tag": conditions.allTags})
+	}
+	sql, args, err := builder.
+		GroupBy("ct.tag").
+		OrderBy("jobs DESC", "ct.tag").
+		Limit(uint64(limit)).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
-	var tags []string
-	err = r.db.Select(&tags, sql, args...)
+	var pos []*TagCountPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	return util.Map(pos, func(po *TagCountPo) *job.TagCount {
+		return &job.TagCount{Tag: po.Tag, Jobs: int(po.Jobs)}
+	}), nil
+}
+
+// findStored returns the stored job with the given link, or nil.
+func findStored(ctx context.Context, q sqlx.QueryerContext, link string) (*JobPo, *job.Job, error) {
+	sql, args, err := sq.Select("*").
+		From("jobs").
+		Where(sq.Eq{"link": link}).
+		ToSql()
+	if err != nil {
+		return nil, nil, err
+	}
+	var jobPos []*JobPo
+	if err := sqlx.SelectContext(ctx, q, &jobPos, sql, args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to select job: %w", err)
+	}
+	if len(jobPos) == 0 {
+		return nil, nil, nil
+	}
+	jobs, err := toJobs(ctx, q, jobPos)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select tags: %w", err)
+		return nil, nil, err
 	}
-	return tags, nil
+	return jobPos[0], jobs[0], nil
 }
 
-func (r *jobRepoImpl) findCategories(jobID int64) (string, string, error) {
-	sql, args, err := sq.Select("main", "sub").
-		From("jobs_categories").
-		Join("categories ON jobs_categories.category_id = categories.id").
+func (r *jobRepoImpl) FindByID(ctx context.Context, id int64) (*job.Job, error) {
+	sql, args, err := sq.Select("*").
+		From("jobs").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var jobPos []*JobPo
+	if err := r.db.SelectContext(ctx, &jobPos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select job: %w", err)
+	}
+	if len(jobPos) == 0 {
+		return nil, nil
+	}
+	jobs, err := toJobs(ctx, r.db, jobPos)
+	if err != nil {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+func (r *jobRepoImpl) FindRevisions(ctx context.Context, jobID int64) ([]*job.Revision, error) {
+	sql, args, err := sq.Select("*").
+		From("job_revisions").
 		Where(sq.Eq{"job_id": jobID}).
+		OrderBy("id").
 		ToSql()
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	var revisionPos []*RevisionPo
+	if err := r.db.SelectContext(ctx, &revisionPos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select job_revisions: %w", err)
+	}
+	revisions := make([]*job.Revision, 0, len(revisionPos))
+	for _, po := range revisionPos {
+		revision, err := po.ToRevision()
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// parsedColumns returns the columns parsed from the free text of the job.
+func parsedColumns(j *job.Job) map[string]interface{} {
+	columns := map[string]interface{}{
+		"experience_years": nil,
+		"salary_min":       nil,
+		"salary_max":       nil,
+		"salary_currency":  "",
+		"salary_period":    job.InvalidSalaryPeriod,
+		"salary_annual":    nil,
+	}
+	if j.ExperienceYears != nil {
+		columns["experience_years"] = *j.ExperienceYears
 	}
-	row := r.db.QueryRowx(sql, args...)
-	if row.Err() != nil {
-		return "", "", fmt.Errorf("failed to select category: %w", err)
+	if s := j.SalaryRange; s != nil {
+		columns["salary_min"] = nullIfZero(s.Min)
+		columns["salary_max"] = nullIfZero(s.Max)
+		columns["salary_currency"] = s.Currency
+		columns["salary_period"] = s.Period
+		columns["salary_annual"] = s.Annual()
 	}
-	s := struct {
-		Main string `db:"main"`
-		Sub  string `db:"sub"`
-	}{}
-	err = row.StructScan(&s)
+	return columns
+}
+
+func nullIfZero(f float64) interface{} {
+	if f == 0 {
+		return nil
+	}
+	return f
+}
+
+func saveRevision(ctx context.Context, tx *database.Tx, jobID, runID int64, changes []job.Change) error {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	var run interface{}
+	if runID != 0 {
+		run = runID
+	}
+	sql, args, err := sq.Insert("job_revisions").
+		Columns("job_id", "run_id", "changes").
+		Values(jobID, run, string(data)).
+		ToSql()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to map scan category: %w", err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to insert job_revision: %w", err)
 	}
-	return s.Main, s.Sub, nil
+	return nil
 }
 
-func (r *jobRepoImpl) FindPaginated(conditions Conditions, page, perPage int64) util.Paginator[*job.Job] {
-	var total int64
-	sql, args, err := conditions.ToSelectBuilder("COUNT(*)").
+func linkRun(ctx context.Context, tx *database.Tx, jobID, runID int64) error {
+	if runID == 0 {
+		return nil
+	}
+	sql, args, err := sq.Insert("jobs_scrape_runs").
+		Columns("job_id", "run_id").
+		Values(jobID, runID).
+		Suffix("ON CONFLICT DO NOTHING").
 		ToSql()
-	util.PanicError(err)
-	err = r.db.Get(&total, sql, args...)
-	util.PanicError(err)
-	return util.NewPaginator(func(offset, limit int64) []*job.Job {
-		var jobPos []*JobPo
-		sql, args, err := conditions.ToSelectBuilder("j.*").Offset(uint64(offset)).Limit(uint64(limit)).ToSql()
-		log.Println(sql, args)
-		util.PanicError(err)
-		err = r.db.Select(&jobPos, sql, args...)
-		util.PanicError(err)
-		var result []*job.Job
-		for _, jobPo := range jobPos {
-			j := jobPo.ToJob()
-			tags, err := r.findTags(jobPo.ID)
-			util.PanicError(err)
-			j.Tags = tags
-			mainCategory, subCategory, err := r.findCategories(jobPo.ID)
-			util.PanicError(err)
-			j.MainCategory = mainCategory
-			j.SubCategory = subCategory
-			result = append(result, j)
-		}
-		return result
-	}, int64(page), int64(perPage), total)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to insert jobs_scrape_runs: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepoImpl) Save(ctx context.Context, j *job.Job, runID int64) (SaveResult, error) {
+	results, err := r.SaveAll(ctx, []*job.Job{j}, runID)
+	if err != nil {
+		return Created, err
+	}
+	return results[0], nil
 }
 
-func (r *jobRepoImpl) Save(j *job.Job) (err error) {
-	tx := r.db.MustBegin()
+func (r *jobRepoImpl) SaveAll(ctx context.Context, jobs []*job.Job, runID int64) (results []SaveResult, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
 	defer func() {
 		if err != nil {
 			_ = tx.Rollback()
@@ -202,11 +509,66 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 		}
 		err = tx.Commit()
 	}()
+	// Tags shared by the jobs are upserted once.
+	tagIDs := map[string]int64{}
+	for _, j := range jobs {
+		result, err := save(ctx, tx, tagIDs, j, runID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save job %s: %w", j.Link, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// save upserts the job in tx. tagIDs are the ids of the tags saved in tx so
+// far, by tag.
+func save(ctx context.Context, tx *database.Tx, tagIDs map[string]int64, j *job.Job, runID int64) (result SaveResult, err error) {
+	storedPo, stored, err := findStored(ctx, tx, j.Link)
+	if err != nil {
+		return result, err
+	}
+	var changes []job.Change
+	if stored != nil {
+		changes = job.Diff(stored, j)
+	}
+	var companyID interface{}
+	if j.CompanySlug != "" {
+		if companyID, err = companyrepo.SaveSlug(ctx, tx, j.CompanySlug, j.Company); err != nil {
+			return result, err
+		}
+	}
+	// Parsed columns are refreshed even when the job is unchanged, picking up
+	// parser improvements.
+	parsed := parsedColumns(j)
+	if stored != nil && len(changes) == 0 {
+		sql, args, err := sq.Update("jobs").
+			SetMap(parsed).
+			Set("company_id", companyID).
+			Set("fetched_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Set("last_seen_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Set("closed_at", nil).
+			Where(sq.Eq{"id": storedPo.ID}).
+			ToSql()
+		if err != nil {
+			return result, err
+		}
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return result, fmt.Errorf("failed to update job: %w", err)
+		}
+		return Unchanged, linkRun(ctx, tx, storedPo.ID, runID)
+	}
+	result = Created
+	if stored != nil {
+		result = Updated
+	}
 	// Save job
 	sql, args, err := sq.Insert("jobs").
 		SetMap(map[string]interface{}{
+			"source":            j.Source,
 			"link":              j.Link,
 			"company":           j.Company,
+			"company_id":        companyID,
 			"title":             j.Title,
 			"employment_type":   j.EmploymentType,
 			"seniority":         j.Seniority,
@@ -214,45 +576,72 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 			"number_to_hire":    j.NumberToHire,
 			"experience":        j.Experience,
 			"salary":            j.Salary,
+			"experience_years":  parsed["experience_years"],
+			"salary_min":        parsed["salary_min"],
+			"salary_max":        parsed["salary_max"],
+			"salary_currency":   parsed["salary_currency"],
+			"salary_period":     parsed["salary_period"],
+			"salary_annual":     parsed["salary_annual"],
 			"remote":            j.Remote,
 			"interview_process": j.InterviewProcess,
 			"job_description":   j.JobDescription,
 			"requirements":      j.Requirements,
+			"fetched_at":        sq.Expr("CURRENT_TIMESTAMP"),
+			"first_seen_at":     sq.Expr("CURRENT_TIMESTAMP"),
+			"last_seen_at":      sq.Expr("CURRENT_TIMESTAMP"),
 		}).
 		Suffix(`
 			ON CONFLICT(link) DO UPDATE SET
+				source = EXCLUDED.source,
+				company = EXCLUDED.company,
+				company_id = EXCLUDED.company_id,
 				title = EXCLUDED.title,
 				employment_type = EXCLUDED.employment_type,
 				seniority = EXCLUDED.seniority,
 				location = EXCLUDED.location,
 				number_to_hire = EXCLUDED.number_to_hire,
 				experience = EXCLUDED.experience,
+				experience_years = EXCLUDED.experience_years,
 				salary = EXCLUDED.salary,
+				salary_min = EXCLUDED.salary_min,
+				salary_max = EXCLUDED.salary_max,
+				salary_currency = EXCLUDED.salary_currency,
+				salary_period = EXCLUDED.salary_period,
+				salary_annual = EXCLUDED.salary_annual,
 				remote = EXCLUDED.remote,
 				interview_process = EXCLUDED.interview_process,
 				job_description = EXCLUDED.job_description,
 				requirements = EXCLUDED.requirements,
-				updated_at = CURRENT_TIMESTAMP
+				updated_at = CURRENT_TIMESTAMP,
+				fetched_at = EXCLUDED.fetched_at,
+				last_seen_at = CURRENT_TIMESTAMP,
+				closed_at = NULL,
+				changed_at = CURRENT_TIMESTAMP
 		`).
 		Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
 	var jobID int64
-	if err = tx.Get(&jobID, sql, args...); err != nil {
-		return fmt.Errorf("failed to insert job: %w", err)
+	if err = tx.GetContext(ctx, &jobID, sql, args...); err != nil {
+		return result, fmt.Errorf("failed to insert job: %w", err)
+	}
+	if result == Updated {
+		if err := saveRevision(ctx, tx, jobID, runID, changes); err != nil {
+			return result, err
+		}
 	}
 	// Save categories
 	sql, args, err = sq.Delete("jobs_categories").
 		Where(sq.Eq{"job_id": jobID}).
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
-	_, err = tx.Exec(sql, args...)
+	_, err = tx.ExecContext(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete jobs_categories: %w", err)
+		return result, fmt.Errorf("failed to delete jobs_categories: %w", err)
 	}
 	sql, args, err = sq.Insert("categories").
 		Columns("main", "sub").
@@ -261,11 +650,11 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 		Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
 	var categoryID int64
-	if err = tx.Get(&categoryID, sql, args...); err != nil {
-		return fmt.Errorf("failed to insert category: %w", err)
+	if err = tx.GetContext(ctx, &categoryID, sql, args...); err != nil {
+		return result, fmt.Errorf("failed to insert category: %w", err)
 	}
 	sql, args, err = sq.Insert("jobs_categories").
 		Columns("job_id", "category_id").
@@ -273,48 +662,42 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 		Suffix("ON CONFLICT DO NOTHING").
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
-	_, err = tx.Exec(sql, args...)
+	_, err = tx.ExecContext(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("failed to insert jobs_categories: %w", err)
+		return result, fmt.Errorf("failed to insert jobs_categories: %w", err)
 	}
 	// Save tags
 	sql, args, err = sq.Delete("jobs_tags").
 		Where(sq.Eq{"job_id": jobID}).
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
-	_, err = tx.Exec(sql, args...)
+	_, err = tx.ExecContext(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete jobs_tags: %w", err)
+		return result, fmt.Errorf("failed to delete jobs_tags: %w", err)
 	}
 	for _, tag := range j.Tags {
-		sql, args, err := sq.Insert("tags").
-			Columns("tag").
-			Values(tag).
-			Suffix("ON CONFLICT(tag) DO UPDATE SET tag = EXCLUDED.tag").
-			Suffix("RETURNING id").
-			ToSql()
-		if err != nil {
-			return err
-		}
-		var tagID int64
-		if err = tx.Get(&tagID, sql, args...); err != nil {
-			return fmt.Errorf("failed to insert tag: %w", err)
+		tagID, ok := tagIDs[tag]
+		if !ok {
+			if tagID, err = tagrepo.Save(ctx, tx, tag); err != nil {
+				return result, err
+			}
+			tagIDs[tag] = tagID
 		}
-		sql, args, err = sq.Insert("jobs_tags").
+		sql, args, err := sq.Insert("jobs_tags").
 			Columns("job_id", "tag_id").
 			Values(jobID, tagID).
 			Suffix("ON CONFLICT DO NOTHING").
 			ToSql()
 		if err != nil {
-			return err
+			return result, err
 		}
-		_, err = tx.Exec(sql, args...)
+		_, err = tx.ExecContext(ctx, sql, args...)
 		if err != nil {
-			return fmt.Errorf("failed to insert jobs_tags: %w", err)
+			return result, fmt.Errorf("failed to insert jobs_tags: %w", err)
 		}
 	}
 	// Save location
@@ -322,11 +705,11 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 		Where(sq.Eq{"job_id": jobID}).
 		ToSql()
 	if err != nil {
-		return err
+		return result, err
 	}
-	_, err = tx.Exec(sql, args...)
+	_, err = tx.ExecContext(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete jobs_locations: %w", err)
+		return result, fmt.Errorf("failed to delete jobs_locations: %w", err)
 	}
 	matchedLocation := location.FindBestMatch(j.Location)
 	if matchedLocation != nil {
@@ -336,35 +719,205 @@ func (r *jobRepoImpl) Save(j *job.Job) (err error) {
 			Where(sq.Eq{"address": matchedLocation.Address()}).
 			ToSql()
 		if err != nil {
-			return err
+			return result, err
 		}
-		if err := tx.Get(&locationID, sql, args...); err != nil {
-			return fmt.Errorf("failed to select location: %w", err)
+		if err := tx.GetContext(ctx, &locationID, sql, args...); err != nil {
+			return result, fmt.Errorf("failed to select location: %w", err)
 		}
 		sql, args, err = sq.Insert("jobs_locations").
 			Columns("job_id", "location_id").
 			Values(jobID, locationID).
 			Suffix("ON CONFLICT DO NOTHING").
 			ToSql()
+		if err != nil {
+			return result, err
+		}
+		_, err = tx.ExecContext(ctx, sql, args...)
+		if err != nil {
+			return result, fmt.Errorf("failed to insert jobs_locations: %w", err)
+		}
+	}
+	return result, linkRun(ctx, tx, jobID, runID)
+}
+
+func (r *jobRepoImpl) LastFetched(ctx context.Context, links []string) (map[string]time.Time, error) {
+	lastFetched := map[string]time.Time{}
+	for _, chunk := range util.Chunk(links, maxChunkSize) {
+		sql, args, err := sq.Select("link", "fetched_at").
+			From("jobs").
+			Where(sq.Eq{"link": chunk}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		var rows []struct {
+			Link      string `db:"link"`
+			FetchedAt Time   `db:"fetched_at"`
+		}
+		if err := r.db.SelectContext(ctx, &rows, sql, args...); err != nil {
+			return nil, fmt.Errorf("failed to select jobs: %w", err)
+		}
+		for _, row := range rows {
+			lastFetched[row.Link] = time.Time(row.FetchedAt)
+		}
+	}
+	return lastFetched, nil
+}
+
+func (r *jobRepoImpl) MarkSeen(ctx context.Context, links []string, runID int64) error {
+	for _, chunk := range util.Chunk(links, maxChunkSize) {
+		sql, args, err := sq.Update("jobs").
+			Set("last_seen_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Set("closed_at", nil).
+			Where(sq.Eq{"link": chunk}).
+			ToSql()
 		if err != nil {
 			return err
 		}
-		_, err = tx.Exec(sql, args...)
+		if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to update jobs: %w", err)
+		}
+		if runID == 0 {
+			continue
+		}
+		sql, args, err = sq.Insert("jobs_scrape_runs").
+			Columns("job_id", "run_id").
+			Select(
+				sq.Select("id").
+					Column("CAST(? AS BIGINT)", runID).
+					From("jobs").
+					Where(sq.Eq{"link": chunk}),
+			).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
 		if err != nil {
-			return fmt.Errorf("failed to insert jobs_locations: %w", err)
+			return err
+		}
+		if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to insert jobs_scrape_runs: %w", err)
 		}
 	}
 	return nil
 }
 
-func (r *jobRepoImpl) Delete(conditions map[string]interface{}) error {
+func (r *jobRepoImpl) Close(ctx context.Context, links []string) (int, error) {
+	closed := 0
+	for _, chunk := range util.Chunk(links, maxChunkSize) {
+		sql, args, err := sq.Update("jobs").
+			Set("closed_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Where(sq.Eq{"link": chunk, "closed_at": nil}).
+			ToSql()
+		if err != nil {
+			return closed, err
+		}
+		res, err := r.db.ExecContext(ctx, sql, args...)
+		if err != nil {
+			return closed, fmt.Errorf("failed to close jobs: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return closed, err
+		}
+		closed += int(n)
+	}
+	return closed, nil
+}
+
+func (r *jobRepoImpl) CloseUnlisted(ctx context.Context, source, listing string, links []string) (closed int, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	// Find the listed jobs
+	listed := map[int64]bool{}
+	for _, chunk := range util.Chunk(links, maxChunkSize) {
+		sql, args, err := sq.Select("id").
+			From("jobs").
+			Where(sq.Eq{"source": source, "link": chunk}).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+		var ids []int64
+		if err := tx.SelectContext(ctx, &ids, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to select jobs: %w", err)
+		}
+		for _, id := range ids {
+			listed[id] = true
+		}
+	}
+	// Find the open jobs listed before
+	sql, args, err := sq.Select("jl.job_id").
+		From("jobs_listings AS jl").
+		Join("jobs AS j ON jl.job_id = j.id").
+		Where(sq.Eq{"jl.listing": listing, "j.source": source, "j.closed_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var previous []int64
+	if err := tx.SelectContext(ctx, &previous, sql, args...); err != nil {
+		return 0, fmt.Errorf("failed to select jobs_listings: %w", err)
+	}
+	unlisted := util.Filter(previous, func(id int64) bool {
+		return !listed[id]
+	})
+	// Close the unlisted jobs
+	for _, chunk := range util.Chunk(unlisted, maxChunkSize) {
+		sql, args, err := sq.Update("jobs").
+			Set("closed_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Where(sq.Eq{"id": chunk}).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to close jobs: %w", err)
+		}
+		sql, args, err = sq.Delete("jobs_listings").
+			Where(sq.Eq{"listing": listing, "job_id": chunk}).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to delete jobs_listings: %w", err)
+		}
+	}
+	// Save the listing
+	for _, chunk := range util.Chunk(slices.Collect(maps.Keys(listed)), maxChunkSize) {
+		builder := sq.Insert("jobs_listings").
+			Columns("job_id", "listing").
+			Suffix("ON CONFLICT DO NOTHING")
+		for _, id := range chunk {
+			builder = builder.Values(id, listing)
+		}
+		sql, args, err := builder.ToSql()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to insert jobs_listings: %w", err)
+		}
+	}
+	return len(unlisted), nil
+}
+
+func (r *jobRepoImpl) Delete(ctx context.Context, conditions map[string]interface{}) error {
 	sql, args, err := sq.Delete("jobs").
 		Where(conditions).
 		ToSql()
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(sql, args...)
+	_, err = r.db.ExecContext(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete jobs: %w", err)
 	}
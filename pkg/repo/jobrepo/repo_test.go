@@ -0,0 +1,503 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/database/dbtest"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/repo/locationrepo"
+	"cake-scraper/pkg/repo/runrepo"
+	"cake-scraper/pkg/run"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/suite"
+)
+
+// JobRepoTestSuite is the contract every JobRepo implementation must meet. It
+// runs against a new repo of one implementation per test.
+type JobRepoTestSuite struct {
+	suite.Suite
+	newRepo func(t testing.TB) JobRepo
+	repo    JobRepo
+}
+
+func (s *JobRepoTestSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+}
+
+// implementation is a JobRepo implementation to run the suite against.
+type implementation struct {
+	name    string
+	newRepo func(t testing.TB) JobRepo
+}
+
+// implementations returns the in-memory repo and a database repo per backend.
+func implementations() []implementation {
+	implementations := []implementation{{
+		name:    "memory",
+		newRepo: func(t testing.TB) JobRepo { return NewMemoryJobRepo() },
+	}}
+	for _, backend := range dbtest.Backends() {
+		implementations = append(implementations, implementation{
+			name: backend.Name,
+			newRepo: func(t testing.TB) JobRepo {
+				return newDBRepo(t, backend.Open(t))
+			},
+		})
+	}
+	return implementations
+}
+
+// newDBRepo returns a repo over db with the known locations saved.
+func newDBRepo(t testing.TB, db *database.DB) *jobRepoImpl {
+	t.Helper()
+	if err := locationrepo.NewLocationRepoWithDB(db).Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return NewJobRepoWithDB(db)
+}
+
+// Links of the jobs saved by saveJobs.
+const (
+	backendLink  = "https://www.cake.me/companies/acme/jobs/backend-engineer"
+	frontendLink = "https://www.cake.me/companies/beta/jobs/frontend-engineer"
+	analystLink  = "https://www.cake.me/companies/acme/jobs/data-analyst"
+)
+
+func newJob(link, company, title string) *job.Job {
+	j := job.New()
+	j.Source = "cake"
+	j.Link = link
+	j.Company = company
+	j.Title = title
+	return j
+}
+
+// saveJobs saves a backend engineer in Taipei, a frontend engineer in
+// Taichung and a remote data analyst.
+func (s *JobRepoTestSuite) saveJobs() {
+	backend := newJob(backendLink, "Acme", "Backend Engineer")
+	backend.CompanySlug = "acme"
+	backend.Location = "Taipei City, Taiwan"
+	backend.MainCategory, backend.SubCategory = "Software", "Backend"
+	backend.Tags = []string{"golang", "Docker"}
+	backend.JobDescription = "Build APIs in Go."
+	backend.Salary = "1M ~ 1.4M TWD / year"
+	backend.SalaryRange = &job.SalaryRange{Min: 1_000_000, Max: 1_400_000, Currency: "TWD", Period: job.Yearly}
+	frontend := newJob(frontendLink, "Beta", "Frontend Engineer")
+	frontend.CompanySlug = "beta"
+	frontend.Location = "Taichung City, Taiwan"
+	frontend.MainCategory, frontend.SubCategory = "Software", "Frontend"
+	frontend.Tags = []string{"React", "ts", "Docker"}
+	frontend.JobDescription = "Build UIs and Kubernetes deployments."
+	analyst := newJob(analystLink, "Acme", "Data Analyst")
+	analyst.CompanySlug = "acme"
+	analyst.Location = "Remote"
+	analyst.MainCategory = "Data"
+	analyst.Tags = []string{"Python"}
+	analyst.Requirements = "SQL and dashboards."
+	for _, j := range []*job.Job{backend, frontend, analyst} {
+		result, err := s.repo.Save(ctx, j, 0)
+		s.Require().NoError(err)
+		s.Require().Equal(Created, result)
+	}
+}
+
+const maxPerPage = 100
+
+var ctx = context.Background()
+
+// items returns the first page of the jobs matching conditions.
+func (s *JobRepoTestSuite) items(conditions Conditions) []*job.Job {
+	paginator, err := s.repo.FindPaginated(ctx, conditions, 1, maxPerPage)
+	s.Require().NoError(err)
+	jobs, err := paginator.Items()
+	s.Require().NoError(err)
+	return jobs
+}
+
+// titles returns the titles of the jobs matching conditions, by title.
+func (s *JobRepoTestSuite) titles(conditions Conditions) []string {
+	jobs := s.items(conditions.SortBy(Sort{Key: SortTitle}))
+	titles := []string{}
+	for _, j := range jobs {
+		titles = append(titles, j.Title)
+	}
+	return titles
+}
+
+func (s *JobRepoTestSuite) TestSave() {
+	// Given
+	s.saveJobs()
+	stored, err := s.repo.Find(ctx, map[string]interface{}{"link": backendLink})
+	s.Require().NoError(err)
+	s.Require().Len(stored, 1)
+
+	// When
+	unchanged, err := s.repo.Save(ctx, stored[0], 0)
+	s.Require().NoError(err)
+	changed := *stored[0]
+	changed.Title = "Senior Backend Engineer"
+	updated, err := s.repo.Save(ctx, &changed, 0)
+	s.Require().NoError(err)
+
+	// Then
+	s.Equal(Unchanged, unchanged)
+	s.Equal(Updated, updated)
+	j, err := s.repo.FindByID(ctx, stored[0].ID)
+	s.Require().NoError(err)
+	s.Equal("Senior Backend Engineer", j.Title)
+	s.Equal("acme", j.CompanySlug)
+	s.Equal("Software", j.MainCategory)
+	s.Equal("Backend", j.SubCategory)
+	s.ElementsMatch([]string{"golang", "Docker"}, j.Tags)
+	s.Equal(1_200_000.0, j.SalaryRange.Annual())
+	s.WithinDuration(time.Now(), j.FirstSeenAt, time.Minute)
+	s.False(j.Closed())
+	revisions, err := s.repo.FindRevisions(ctx, j.ID)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 1)
+	s.Equal([]job.Change{{Field: "Title", Old: "Backend Engineer", New: "Senior Backend Engineer"}}, revisions[0].Changes)
+}
+
+func (s *JobRepoTestSuite) TestSave_CompanyRenamed() {
+	// Given
+	_, err := s.repo.Save(ctx, newJob(backendLink, "Acme", "Backend Engineer"), 0)
+	s.Require().NoError(err)
+
+	// When
+	renamed, err := s.repo.Save(ctx, newJob(backendLink, "Acme Inc", "Backend Engineer"), 0)
+	s.Require().NoError(err)
+	again, err := s.repo.Save(ctx, newJob(backendLink, "Acme Inc", "Backend Engineer"), 0)
+	s.Require().NoError(err)
+
+	// Then
+	s.Equal(Updated, renamed)
+	s.Equal(Unchanged, again)
+	jobs := s.items(NewConditions().Company("Acme Inc"))
+	s.Require().Len(jobs, 1)
+	s.Equal("Acme Inc", jobs[0].Company)
+	s.Len(s.items(NewConditions().Search("inc")), 1)
+	revisions, err := s.repo.FindRevisions(ctx, jobs[0].ID)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 1)
+	s.Equal([]job.Change{{Field: "Company", Old: "Acme", New: "Acme Inc"}}, revisions[0].Changes)
+}
+
+func (s *JobRepoTestSuite) TestSaveAll() {
+	// Given
+	backend := newJob(backendLink, "Acme", "Backend Engineer")
+	backend.Tags = []string{"golang", "Docker"}
+	frontend := newJob(frontendLink, "Beta", "Frontend Engineer")
+	frontend.Tags = []string{"Docker"}
+	senior := newJob(backendLink, "Acme", "Senior Backend Engineer")
+	senior.Tags = []string{"golang"}
+
+	// When
+	results, err := s.repo.SaveAll(ctx, []*job.Job{backend, frontend, senior}, 0)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal([]SaveResult{Created, Created, Updated}, results)
+	s.Equal([]string{"Frontend Engineer", "Senior Backend Engineer"}, s.titles(NewConditions()))
+	counts, err := s.repo.CountTags(ctx, NewConditions(), 10)
+	s.Require().NoError(err)
+	s.ElementsMatch([]*job.TagCount{{Tag: "Go", Jobs: 1}, {Tag: "Docker", Jobs: 1}}, counts)
+}
+
+func (s *JobRepoTestSuite) TestFindPaginated_Conditions() {
+	// Given
+	s.saveJobs()
+	all := []string{"Backend Engineer", "Data Analyst", "Frontend Engineer"}
+
+	tests := []struct {
+		name       string
+		conditions Conditions
+		want       []string
+	}{
+		{"all", NewConditions(), all},
+		{"company", NewConditions().Company("Acme"), []string{"Backend Engineer", "Data Analyst"}},
+		{"company slug", NewConditions().CompanySlug("beta"), []string{"Frontend Engineer"}},
+		{"any of tags by canonical tag", NewConditions().Tags("go", "python"), []string{"Backend Engineer", "Data Analyst"}},
+		{"all of tags", NewConditions().AllTags("TypeScript", "docker"), []string{"Frontend Engineer"}},
+		{"none of tags", NewConditions().NoTags("Docker"), []string{"Data Analyst"}},
+		{"location ignoring case", NewConditions().Location("TAIPEI"), []string{"Backend Engineer"}},
+		{"place", NewConditions().Places(location.Find("Taichung City")...), []string{"Frontend Engineer"}},
+		{"main category", NewConditions().MainCategory("Software"), []string{"Backend Engineer", "Frontend Engineer"}},
+		{"sub category", NewConditions().SubCategory("Backend"), []string{"Backend Engineer"}},
+		{"salary", NewConditions().Salary(1_000_000, 0), []string{"Backend Engineer"}},
+		{"search", NewConditions().Search("apis"), []string{"Backend Engineer"}},
+		{"search by prefix", NewConditions().Search("kube*"), []string{"Frontend Engineer"}},
+		{"search a phrase", NewConditions().Search(`"build uis"`), []string{"Frontend Engineer"}},
+		{"search every term", NewConditions().Search("build dashboards"), []string{}},
+		{"search without terms", NewConditions().Search(`""`), all},
+		{"new since", NewConditions().NewSince(time.Now().Add(-time.Hour)), all},
+		{"new since later", NewConditions().NewSince(time.Now().Add(time.Hour)), []string{}},
+		{"active", NewConditions().Active(), all},
+		{"closed", NewConditions().Closed(), []string{}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.want, s.titles(tt.conditions))
+		})
+	}
+}
+
+func (s *JobRepoTestSuite) TestFindPaginated_Pages() {
+	// Given
+	s.saveJobs()
+
+	// When
+	paginator, err := s.repo.FindPaginated(ctx, NewConditions().SortBy(Sort{Key: SortSalary, Desc: true}), 2, 2)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(int64(3), paginator.Total())
+	s.Equal(int64(2), paginator.TotalPage())
+	first, err := paginator.Prev().Items()
+	s.Require().NoError(err)
+	s.Require().Len(first, 2)
+	s.Equal("Backend Engineer", first[0].Title)
+	last, err := paginator.Items()
+	s.Require().NoError(err)
+	s.Len(last, 1)
+}
+
+func (s *JobRepoTestSuite) TestFindPaginated_Snippet() {
+	// Given
+	s.saveJobs()
+
+	// When
+	jobs := s.items(NewConditions().Search("apis"))
+
+	// Then
+	s.Require().Len(jobs, 1)
+	s.Contains(jobs[0].Snippet, job.HighlightStart+"APIs"+job.HighlightEnd)
+}
+
+func (s *JobRepoTestSuite) TestCountTags() {
+	// Given
+	s.saveJobs()
+
+	// When
+	counts, err := s.repo.CountTags(ctx, NewConditions(), 10)
+	s.Require().NoError(err)
+	coOccurring, err := s.repo.CountTags(ctx, NewConditions().AllTags("Docker"), 10)
+	s.Require().NoError(err)
+
+	// Then
+	s.Require().Len(counts, 5)
+	s.Equal(&job.TagCount{Tag: "Docker", Jobs: 2}, counts[0])
+	s.ElementsMatch([]*job.TagCount{
+		{Tag: "Go", Jobs: 1},
+		{Tag: "React", Jobs: 1},
+		{Tag: "TypeScript", Jobs: 1},
+		{Tag: "Python", Jobs: 1},
+	}, counts[1:])
+	s.ElementsMatch([]*job.TagCount{
+		{Tag: "Go", Jobs: 1},
+		{Tag: "React", Jobs: 1},
+		{Tag: "TypeScript", Jobs: 1},
+	}, coOccurring)
+}
+
+func (s *JobRepoTestSuite) TestLastFetched() {
+	// Given
+	s.saveJobs()
+
+	// When
+	lastFetched, err := s.repo.LastFetched(ctx, []string{backendLink, "https://www.cake.me/unknown"})
+
+	// Then
+	s.Require().NoError(err)
+	s.Len(lastFetched, 1)
+	s.WithinDuration(time.Now(), lastFetched[backendLink], time.Minute)
+}
+
+func (s *JobRepoTestSuite) TestCloseAndMarkSeen() {
+	// Given
+	s.saveJobs()
+
+	// When
+	closed, err := s.repo.Close(ctx, []string{backendLink, frontendLink})
+	s.Require().NoError(err)
+	closedAgain, err := s.repo.Close(ctx, []string{backendLink})
+	s.Require().NoError(err)
+	s.Equal([]string{"Backend Engineer", "Frontend Engineer"}, s.titles(NewConditions().Closed()))
+	err = s.repo.MarkSeen(ctx, []string{frontendLink}, 0)
+	s.Require().NoError(err)
+
+	// Then
+	s.Equal(2, closed)
+	s.Equal(0, closedAgain)
+	s.Equal([]string{"Backend Engineer"}, s.titles(NewConditions().Closed()))
+}
+
+func (s *JobRepoTestSuite) TestCloseUnlisted() {
+	// Given
+	s.saveJobs()
+	closed, err := s.repo.CloseUnlisted(ctx, "cake", "engineers", []string{backendLink, frontendLink})
+	s.Require().NoError(err)
+	s.Equal(0, closed)
+
+	// When
+	closed, err = s.repo.CloseUnlisted(ctx, "cake", "engineers", []string{backendLink})
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(1, closed)
+	s.Equal([]string{"Frontend Engineer"}, s.titles(NewConditions().Closed()))
+}
+
+func (s *JobRepoTestSuite) TestCanceledContext() {
+	// Given
+	s.saveJobs()
+	canceled, cancel := context.WithCancel(ctx)
+	paginator, err := s.repo.FindPaginated(ctx, NewConditions(), 1, maxPerPage)
+	s.Require().NoError(err)
+
+	// When
+	cancel()
+	_, findErr := s.repo.Find(canceled, nil)
+	_, paginateErr := s.repo.FindPaginated(canceled, NewConditions(), 1, maxPerPage)
+	_, saveErr := s.repo.Save(canceled, newJob(backendLink, "Acme", "Backend Engineer"), 0)
+
+	// Then
+	s.ErrorIs(findErr, context.Canceled)
+	s.ErrorIs(paginateErr, context.Canceled)
+	s.ErrorIs(saveErr, context.Canceled)
+	jobs, err := paginator.Items()
+	s.Require().NoError(err)
+	s.Len(jobs, 3)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestJobRepoTestSuite(t *testing.T) {
+	for _, impl := range implementations() {
+		t.Run(impl.name, func(t *testing.T) {
+			suite.Run(t, &JobRepoTestSuite{newRepo: impl.newRepo})
+		})
+	}
+}
+
+func TestMarkSeen_LinksRun(t *testing.T) {
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			// Given
+			db := backend.Open(t)
+			repo := newDBRepo(t, db)
+			runs := runrepo.NewRunRepoWithDB(db)
+			scrapeRun := run.New(time.Now())
+			if err := runs.Save(ctx, scrapeRun); err != nil {
+				t.Fatal(err)
+			}
+			for _, link := range []string{backendLink, frontendLink} {
+				if _, err := repo.Save(ctx, newJob(link, "Acme", "Engineer"), 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// When
+			err := repo.MarkSeen(ctx, []string{frontendLink}, scrapeRun.ID)
+
+			// Then
+			if err != nil {
+				t.Fatal(err)
+			}
+			links, err := runs.FindJobLinks(ctx, scrapeRun.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(links) != 1 || links[0] != frontendLink {
+				t.Errorf("links = %v, want [%s]", links, frontendLink)
+			}
+		})
+	}
+}
+
+const fixtureJobs = 10000
+
+// newFixtureRepo returns a repo over a new SQLite database of jobs jobs, each
+// with three tags out of fifty and one category out of twenty.
+func newFixtureRepo(b *testing.B, jobs int) *jobRepoImpl {
+	b.Helper()
+	db := dbtest.OpenSQLite(b)
+	tx := db.MustBegin()
+	for i := 1; i <= 50; i++ {
+		tx.MustExec("INSERT INTO tags (id, tag) VALUES (?, ?)", i, fmt.Sprint("tag-", i))
+	}
+	for i := 1; i <= 20; i++ {
+		tx.MustExec("INSERT INTO categories (id, main, sub) VALUES (?, ?, ?)", i, fmt.Sprint("main-", i%4), fmt.Sprint("sub-", i))
+	}
+	for id := 1; id <= jobs; id++ {
+		sql, args, err := sq.Insert("jobs").
+			SetMap(map[string]interface{}{
+				"id":              id,
+				"source":          "cake",
+				"company":         fmt.Sprint("company-", id%300),
+				"title":           fmt.Sprint("title-", id),
+				"link":            fmt.Sprint("https://www.cake.me/companies/c/jobs/", id),
+				"employment_type": 0,
+				"seniority":       0,
+				"location":        "Taipei City, Taiwan",
+				"remote":          0,
+				"job_description": "description",
+				"requirements":    "requirements",
+				"first_seen_at":   "2024-01-01 00:00:00",
+				"last_seen_at":    "2024-01-01 00:00:00",
+			}).
+			ToSql()
+		if err != nil {
+			b.Fatal(err)
+		}
+		tx.MustExec(sql, args...)
+		for k := 0; k < 3; k++ {
+			tx.MustExec("INSERT INTO jobs_tags (job_id, tag_id) VALUES (?, ?)", id, (id+k*17)%50+1)
+		}
+		tx.MustExec("INSERT INTO jobs_categories (job_id, category_id) VALUES (?, ?)", id, id%20+1)
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return NewJobRepoWithDB(db)
+}
+
+func BenchmarkFind(b *testing.B) {
+	repo := newFixtureRepo(b, fixtureJobs)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jobs, err := repo.Find(ctx, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(jobs) != fixtureJobs || len(jobs[0].Tags) != 3 || jobs[0].MainCategory == "" {
+			b.Fatalf("unexpected jobs: %d", len(jobs))
+		}
+	}
+}
+
+func BenchmarkFindPaginated(b *testing.B) {
+	repo := newFixtureRepo(b, fixtureJobs)
+	paginator, err := repo.FindPaginated(ctx, NewConditions(), 1, 100)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jobs, err := paginator.Items()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(jobs) != 100 || len(jobs[0].Tags) != 3 || jobs[0].MainCategory == "" {
+			b.Fatalf("unexpected jobs: %d", len(jobs))
+		}
+	}
+}
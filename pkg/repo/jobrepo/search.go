@@ -0,0 +1,118 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/job"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	// searchRank orders SQLite search results by relevance, weighing title,
+	// company, description and requirements matches in that order.
+	searchRank = "bm25(jobs_fts, 10.0, 5.0, 1.0, 1.0)"
+	// postgresSearchRank orders PostgreSQL search results by relevance. The
+	// document weighs the columns like bm25 does.
+	postgresSearchRank = "ts_rank(jobs_fts.document, to_tsquery('simple', ?)) DESC"
+)
+
+// searchTermRegex matches the terms of a search: quoted phrases and words,
+// either optionally followed by * for prefix matching.
+var searchTermRegex = regexp.MustCompile(`"([^"]*)"(\*?)|([^\s"*]+)(\*?)`)
+
+type searchTerm struct {
+	text   string
+	prefix bool
+}
+
+// searchTerms returns the terms of a search typed by a user. "Quoted words"
+// are a phrase and a trailing * matches words by prefix.
+func searchTerms(search string) []searchTerm {
+	terms := []searchTerm{}
+	for _, match := range searchTermRegex.FindAllStringSubmatch(search, -1) {
+		text, prefix := match[1], match[2]
+		if match[3] != "" {
+			text, prefix = match[3], match[4]
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		terms = append(terms, searchTerm{text: text, prefix: prefix != ""})
+	}
+	return terms
+}
+
+// ftsQuery turns a search typed by a user into an FTS5 query that matches
+// jobs containing every term. Other FTS5 syntax is matched literally, so that
+// no search is a syntax error.
+func ftsQuery(search string) string {
+	terms := []string{}
+	for _, term := range searchTerms(search) {
+		query := `"` + term.text + `"`
+		if term.prefix {
+			query += "*"
+		}
+		terms = append(terms, query)
+	}
+	return strings.Join(terms, " ")
+}
+
+// tsQueryEscaper escapes a term quoted in a PostgreSQL tsquery.
+var tsQueryEscaper = strings.NewReplacer(`\`, `\\`, `'`, `''`)
+
+// tsQuery turns a search typed by a user into a PostgreSQL tsquery like
+// ftsQuery. A quoted term of several words matches them as a phrase.
+func tsQuery(search string) string {
+	terms := []string{}
+	for _, term := range searchTerms(search) {
+		query := "'" + tsQueryEscaper.Replace(term.text) + "'"
+		if term.prefix {
+			query += ":*"
+		}
+		terms = append(terms, query)
+	}
+	return strings.Join(terms, " & ")
+}
+
+// whereSearch keeps the jobs of builder matching search.
+func whereSearch(dialect database.Dialect, builder sq.SelectBuilder, search string) sq.SelectBuilder {
+	if dialect == database.Postgres {
+		return builder.
+			Join("jobs_fts ON jobs_fts.job_id = j.id").
+			Where("jobs_fts.document @@ to_tsquery('simple', ?)", tsQuery(search))
+	}
+	return builder.
+		Join("jobs_fts ON jobs_fts.rowid = j.id").
+		Where("jobs_fts MATCH ?", ftsQuery(search))
+}
+
+// orderBySearchRank orders the jobs of builder by how well they match search.
+func orderBySearchRank(dialect database.Dialect, builder sq.SelectBuilder, search string) sq.SelectBuilder {
+	if dialect == database.Postgres {
+		return builder.OrderByClause(postgresSearchRank, tsQuery(search))
+	}
+	return builder.OrderBy(searchRank)
+}
+
+// selectSnippet selects the excerpt of each job matching search as snippet,
+// with the matches enclosed in job.HighlightStart and job.HighlightEnd.
+func selectSnippet(dialect database.Dialect, builder sq.SelectBuilder, search string) sq.SelectBuilder {
+	if dialect == database.Postgres {
+		options := `StartSel="` + job.HighlightStart + `", StopSel="` + job.HighlightEnd + `", MaxWords=16, MinWords=8`
+		return builder.Column(
+			"ts_headline('simple', concat_ws(' ', j.title, j.company, j.job_description, j.requirements), to_tsquery('simple', ?), ?) AS snippet",
+			tsQuery(search), options,
+		)
+	}
+	return builder.Column("snippet(jobs_fts, -1, ?, ?, '…', 16) AS snippet", job.HighlightStart, job.HighlightEnd)
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern. SQLite has no default
+// escape character, so patterns using it need an ESCAPE clause.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,55 @@
+package jobrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchTestSuite struct {
+	suite.Suite
+}
+
+func (s *SearchTestSuite) TestFtsQuery() {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"golang", `"golang"`},
+		{"backend golang", `"backend" "golang"`},
+		{`"machine learning" python`, `"machine learning" "python"`},
+		{"kube*", `"kube"*`},
+		{`"data eng"*`, `"data eng"*`},
+		{"c++ -java title:go", `"c++" "-java" "title:go"`},
+		{`unbalanced "quote`, `"unbalanced" "quote"`},
+		{`  "" * `, ``},
+	}
+	for _, tt := range tests {
+		s.Run(tt.search, func() {
+			s.Equal(tt.want, ftsQuery(tt.search))
+		})
+	}
+}
+
+func (s *SearchTestSuite) TestTsQuery() {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"golang", `'golang'`},
+		{"backend golang", `'backend' & 'golang'`},
+		{`"machine learning" python`, `'machine learning' & 'python'`},
+		{"kube*", `'kube':*`},
+		{`it's c:\`, `'it''s' & 'c:\\'`},
+		{`  "" * `, ``},
+	}
+	for _, tt := range tests {
+		s.Run(tt.search, func() {
+			s.Equal(tt.want, tsQuery(tt.search))
+		})
+	}
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
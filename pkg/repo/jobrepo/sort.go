@@ -0,0 +1,112 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type SortKey int
+
+const (
+	SortFirstSeen SortKey = iota
+	SortUpdated
+	SortCompany
+	SortTitle
+	SortSalary
+	SortNumberToHire
+	InvalidSortKey SortKey = -1
+)
+
+// sortColumns are the expressions jobs are ordered by for each sort key.
+var sortColumns = map[SortKey]string{
+	SortFirstSeen:    "j.first_seen_at",
+	SortUpdated:      "j.updated_at",
+	SortCompany:      "LOWER(j.company)",
+	SortTitle:        "LOWER(j.title)",
+	SortSalary:       "j.salary_annual",
+	SortNumberToHire: "j.number_to_hire",
+}
+
+func NewSortKey(s string) SortKey {
+	switch s {
+	case "first_seen", "posted":
+		return SortFirstSeen
+	case "updated":
+		return SortUpdated
+	case "company":
+		return SortCompany
+	case "title":
+		return SortTitle
+	case "salary":
+		return SortSalary
+	case "number_to_hire":
+		return SortNumberToHire
+	default:
+		return InvalidSortKey
+	}
+}
+
+func (k SortKey) String() string {
+	switch k {
+	case SortFirstSeen:
+		return "first_seen"
+	case SortUpdated:
+		return "updated"
+	case SortCompany:
+		return "company"
+	case SortTitle:
+		return "title"
+	case SortSalary:
+		return "salary"
+	case SortNumberToHire:
+		return "number_to_hire"
+	default:
+		return "Invalid"
+	}
+}
+
+// Sort is a sort key and direction.
+type Sort struct {
+	Key  SortKey
+	Desc bool
+}
+
+// ParseSort parses a sort key, prefixed by "-" for descending order.
+func ParseSort(s string) (Sort, error) {
+	sort := Sort{Key: NewSortKey(strings.TrimPrefix(s, "-")), Desc: strings.HasPrefix(s, "-")}
+	if sort.Key == InvalidSortKey {
+		return Sort{}, fmt.Errorf("unknown sort key %q", s)
+	}
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Key.String()
+	}
+	return s.Key.String()
+}
+
+// orderBy orders builder by the sorts of the conditions, then by relevance
+// when searching, then by id so that pages are stable. Jobs missing the sorted
+// value come last in either direction.
+func (c Conditions) orderBy(dialect database.Dialect, builder sq.SelectBuilder) sq.SelectBuilder {
+	for _, sort := range c.sorts {
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		builder = builder.OrderBy(sortColumns[sort.Key] + " " + direction + " NULLS LAST")
+	}
+	if c.search != "" {
+		builder = orderBySearchRank(dialect, builder, c.search)
+	}
+	tieBreak := "j.id"
+	if len(c.sorts) > 0 && c.sorts[len(c.sorts)-1].Desc {
+		tieBreak = "j.id DESC"
+	}
+	return builder.OrderBy(tieBreak)
+}
@@ -0,0 +1,89 @@
+package jobrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SortTestSuite struct {
+	suite.Suite
+}
+
+func (s *SortTestSuite) TestParseSort() {
+	tests := []struct {
+		sort    string
+		want    Sort
+		wantErr bool
+	}{
+		{"salary", Sort{Key: SortSalary}, false},
+		{"-first_seen", Sort{Key: SortFirstSeen, Desc: true}, false},
+		{"-posted", Sort{Key: SortFirstSeen, Desc: true}, false},
+		{"number_to_hire", Sort{Key: SortNumberToHire}, false},
+		{"--title", Sort{}, true},
+		{"id", Sort{}, true},
+	}
+	for _, tt := range tests {
+		s.Run(tt.sort, func() {
+			sort, err := ParseSort(tt.sort)
+			if tt.wantErr {
+				s.Error(err)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tt.want, sort)
+		})
+	}
+}
+
+func (s *SortTestSuite) TestOrderBy() {
+	tests := []struct {
+		name       string
+		dialect    database.Dialect
+		conditions Conditions
+		want       string
+		wantArgs   []any
+	}{
+		{
+			"default",
+			database.SQLite,
+			NewConditions(),
+			"SELECT j.id FROM jobs AS j ORDER BY j.id",
+			nil,
+		},
+		{
+			"descending breaks ties by newest id",
+			database.SQLite,
+			NewConditions().SortBy(Sort{Key: SortCompany}, Sort{Key: SortSalary, Desc: true}),
+			"SELECT j.id FROM jobs AS j ORDER BY LOWER(j.company) ASC NULLS LAST, j.salary_annual DESC NULLS LAST, j.id DESC",
+			nil,
+		},
+		{
+			"search ranks after sorts",
+			database.SQLite,
+			NewConditions().Search("go").SortBy(Sort{Key: SortFirstSeen}),
+			"SELECT j.id FROM jobs AS j JOIN jobs_fts ON jobs_fts.rowid = j.id WHERE jobs_fts MATCH ? ORDER BY j.first_seen_at ASC NULLS LAST, " + searchRank + ", j.id",
+			[]any{`"go"`},
+		},
+		{
+			"search ranks after sorts in PostgreSQL",
+			database.Postgres,
+			NewConditions().Search("go").SortBy(Sort{Key: SortFirstSeen}),
+			"SELECT j.id FROM jobs AS j JOIN jobs_fts ON jobs_fts.job_id = j.id WHERE jobs_fts.document @@ to_tsquery('simple', ?) ORDER BY j.first_seen_at ASC NULLS LAST, " + postgresSearchRank + ", j.id",
+			[]any{"'go'", "'go'"},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			sql, args, err := tt.conditions.orderBy(tt.dialect, tt.conditions.ToSelectBuilder(tt.dialect, "j.id")).ToSql()
+			s.NoError(err)
+			s.Equal(tt.want, sql)
+			s.Equal(tt.wantArgs, args)
+		})
+	}
+}
+
+func TestSortTestSuite(t *testing.T) {
+	suite.Run(t, new(SortTestSuite))
+}
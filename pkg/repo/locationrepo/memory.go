@@ -0,0 +1,102 @@
+package locationrepo
+
+import (
+	"cake-scraper/pkg/location"
+	"cake-scraper/pkg/util"
+	"context"
+	"fmt"
+	"sync"
+)
+
+var (
+	_ LocationRepo = (*memoryLocationRepo)(nil)
+)
+
+// memoryLocationRepo keeps locations in memory, in the order they were first
+// saved.
+type memoryLocationRepo struct {
+	mu        sync.RWMutex
+	locations []*LocationPo
+}
+
+func NewMemoryLocationRepo() *memoryLocationRepo {
+	return &memoryLocationRepo{}
+}
+
+// memoryColumns are the columns Find can match by.
+var memoryColumns = map[string]func(po *LocationPo) any{
+	"id":       func(po *LocationPo) any { return po.ID },
+	"address":  func(po *LocationPo) any { return po.Address },
+	"country":  func(po *LocationPo) any { return po.Country },
+	"city":     func(po *LocationPo) any { return po.City },
+	"area":     func(po *LocationPo) any { return po.Area },
+	"zip_code": func(po *LocationPo) any { return po.ZipCode },
+}
+
+func (r *memoryLocationRepo) Init(ctx context.Context) error {
+	return r.SaveAll(ctx, location.LoadLocations())
+}
+
+func (r *memoryLocationRepo) Find(ctx context.Context, conditions map[string]interface{}) ([]*location.Location, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for column := range conditions {
+		if memoryColumns[column] == nil {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var locations []*location.Location
+	for _, po := range r.locations {
+		matched := true
+		for column, want := range conditions {
+			if !util.MatchValue(memoryColumns[column](po), want) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			locations = append(locations, &location.Location{
+				Country: po.Country,
+				City:    po.City,
+				Area:    po.Area,
+				ZipCode: po.ZipCode,
+			})
+		}
+	}
+	return locations, nil
+}
+
+func (r *memoryLocationRepo) Save(ctx context.Context, l *location.Location) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, po := range r.locations {
+		if po.Address == l.Address() {
+			po.Country, po.City, po.Area, po.ZipCode = l.Country, l.City, l.Area, l.ZipCode
+			return nil
+		}
+	}
+	r.locations = append(r.locations, &LocationPo{
+		ID:      int64(len(r.locations) + 1),
+		Address: l.Address(),
+		Country: l.Country,
+		City:    l.City,
+		Area:    l.Area,
+		ZipCode: l.ZipCode,
+	})
+	return nil
+}
+
+func (r *memoryLocationRepo) SaveAll(ctx context.Context, locations []*location.Location) error {
+	for _, l := range locations {
+		if err := r.Save(ctx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package locationrepo
 import (
 	"cake-scraper/pkg/database"
 	"cake-scraper/pkg/location"
+	"context"
 	"log/slog"
 
 	sq "github.com/Masterminds/squirrel"
@@ -22,10 +23,10 @@ type LocationPo struct {
 }
 
 type LocationRepo interface {
-	Init() error
-	Find(conditions map[string]interface{}) ([]*location.Location, error)
-	Save(l *location.Location) error
-	SaveAll(locations []*location.Location) error
+	Init(ctx context.Context) error
+	Find(ctx context.Context, conditions map[string]interface{}) ([]*location.Location, error)
+	Save(ctx context.Context, l *location.Location) error
+	SaveAll(ctx context.Context, locations []*location.Location) error
 }
 
 type locationRepoImpl struct {
@@ -40,25 +41,30 @@ func NewLocationRepo() *locationRepoImpl {
 	return &locationRepoImpl{db: db}
 }
 
-func (r *locationRepoImpl) Init() error {
+// NewLocationRepoWithDB returns a repo over db instead of the shared database.
+func NewLocationRepoWithDB(db *database.DB) *locationRepoImpl {
+	return &locationRepoImpl{db: db}
+}
+
+func (r *locationRepoImpl) Init(ctx context.Context) error {
 	locations := location.LoadLocations()
-	if err := r.SaveAll(locations); err != nil {
+	if err := r.SaveAll(ctx, locations); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *locationRepoImpl) Find(conditions map[string]interface{}) ([]*location.Location, error) {
+func (r *locationRepoImpl) Find(ctx context.Context, conditions map[string]interface{}) ([]*location.Location, error) {
 	var locations []*location.Location
 	var pos []*LocationPo
 	sql, args, err := sq.Select("*").
-		From("location").
+		From("locations").
 		Where(conditions).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
-	if err := r.db.Select(&pos, sql, args...); err != nil {
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
 		return nil, err
 	}
 	for _, po := range pos {
@@ -72,7 +78,7 @@ func (r *locationRepoImpl) Find(conditions map[string]interface{}) ([]*location.
 	return locations, nil
 }
 
-func (r *locationRepoImpl) Save(l *location.Location) error {
+func (r *locationRepoImpl) Save(ctx context.Context, l *location.Location) error {
 	sql, args, err := sq.Insert("locations").
 		Columns("address", "country", "city", "area", "zip_code").
 		Values(l.Address(), l.Country, l.City, l.Area, l.ZipCode).
@@ -86,22 +92,22 @@ func (r *locationRepoImpl) Save(l *location.Location) error {
 	if err != nil {
 		return err
 	}
-	if _, err := r.db.Exec(sql, args...); err != nil {
+	if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *locationRepoImpl) SaveAll(locations []*location.Location) error {
-	tx, err := r.db.Beginx()
+func (r *locationRepoImpl) SaveAll(ctx context.Context, locations []*location.Location) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		slog.Error("failed to start transaction", "error", err)
 		return err
 	}
 	defer func() {
 		if err != nil {
-			slog.Error("failed to commit transaction", "error", err)
-			err = tx.Rollback()
+			_ = tx.Rollback()
+			return
 		}
 		err = tx.Commit()
 	}()
@@ -119,7 +125,7 @@ func (r *locationRepoImpl) SaveAll(locations []*location.Location) error {
 		if err != nil {
 			return err
 		}
-		if _, err := tx.Exec(sql, args...); err != nil {
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
 			return err
 		}
 	}
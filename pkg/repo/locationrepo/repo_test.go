@@ -0,0 +1,74 @@
+package locationrepo
+
+import (
+	"cake-scraper/pkg/database/dbtest"
+	"cake-scraper/pkg/location"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LocationRepoTestSuite is the contract every LocationRepo implementation
+// must meet. It runs against a new repo of one implementation per test.
+type LocationRepoTestSuite struct {
+	suite.Suite
+	newRepo func(t testing.TB) LocationRepo
+	repo    LocationRepo
+}
+
+var ctx = context.Background()
+
+func (s *LocationRepoTestSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+}
+
+func (s *LocationRepoTestSuite) TestInit() {
+	// When
+	s.Require().NoError(s.repo.Init(ctx))
+	s.Require().NoError(s.repo.Init(ctx))
+
+	// Then
+	locations, err := s.repo.Find(ctx, nil)
+	s.Require().NoError(err)
+	s.Len(locations, len(location.LoadLocations()))
+	taipei, err := s.repo.Find(ctx, map[string]interface{}{"city": "Taipei City", "area": "Zhongzheng District"})
+	s.Require().NoError(err)
+	s.Equal([]*location.Location{location.NewLocation("Taiwan", "Taipei City", "Zhongzheng District", "100")}, taipei)
+}
+
+func (s *LocationRepoTestSuite) TestSave() {
+	// Given
+	l := location.NewLocation("Taiwan", "Taipei City", "Zhongzheng District", "100")
+	s.Require().NoError(s.repo.Save(ctx, l))
+
+	// When
+	l.ZipCode = "999"
+	err := s.repo.Save(ctx, l)
+
+	// Then
+	s.Require().NoError(err)
+	locations, err := s.repo.Find(ctx, nil)
+	s.Require().NoError(err)
+	s.Equal([]*location.Location{l}, locations)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestLocationRepoTestSuite(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		suite.Run(t, &LocationRepoTestSuite{newRepo: func(t testing.TB) LocationRepo {
+			return NewMemoryLocationRepo()
+		}})
+	})
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &LocationRepoTestSuite{newRepo: func(t testing.TB) LocationRepo {
+				return NewLocationRepoWithDB(backend.Open(t))
+			}})
+		})
+	}
+}
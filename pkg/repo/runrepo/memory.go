@@ -0,0 +1,99 @@
+package runrepo
+
+import (
+	"cake-scraper/pkg/run"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	_ RunRepo = (*memoryRunRepo)(nil)
+)
+
+// memoryRunRepo keeps runs in memory, in the order they were started.
+type memoryRunRepo struct {
+	mu       sync.RWMutex
+	runs     []*run.Run
+	jobLinks func(runID int64) []string
+}
+
+// NewMemoryRunRepo returns a repo keeping runs in memory. jobLinks returns the
+// links of the jobs a run saw, as the RunLinks of a memory job repo does; if
+// it is nil, runs saw no jobs.
+func NewMemoryRunRepo(jobLinks func(runID int64) []string) *memoryRunRepo {
+	return &memoryRunRepo{jobLinks: jobLinks}
+}
+
+func cloneRun(r *run.Run) *run.Run {
+	clone := *r
+	clone.Sources = append([]string{}, r.Sources...)
+	clone.Professions = append([]string{}, r.Professions...)
+	clone.ErrorMessages = append([]string{}, r.ErrorMessages...)
+	return &clone
+}
+
+func (r *memoryRunRepo) FindLatest(ctx context.Context, limit int64) ([]*run.Run, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	runs := []*run.Run{}
+	for i := len(r.runs) - 1; i >= 0 && int64(len(runs)) < limit; i-- {
+		runs = append(runs, cloneRun(r.runs[i]))
+	}
+	return runs, nil
+}
+
+func (r *memoryRunRepo) FindByID(ctx context.Context, id int64) (*run.Run, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i := slices.IndexFunc(r.runs, func(stored *run.Run) bool {
+		return stored.ID == id
+	})
+	if i < 0 {
+		return nil, nil
+	}
+	return cloneRun(r.runs[i]), nil
+}
+
+func (r *memoryRunRepo) FindJobLinks(ctx context.Context, id int64) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.jobLinks == nil {
+		return []string{}, nil
+	}
+	return r.jobLinks(id), nil
+}
+
+func (r *memoryRunRepo) Save(ctx context.Context, scrapeRun *run.Run) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := cloneRun(scrapeRun)
+	// Times are kept at the precision the database stores.
+	saved.StartedAt = saved.StartedAt.UTC().Truncate(time.Second)
+	if !saved.FinishedAt.IsZero() {
+		saved.FinishedAt = saved.FinishedAt.UTC().Truncate(time.Second)
+	}
+	if scrapeRun.ID != 0 {
+		if i := slices.IndexFunc(r.runs, func(stored *run.Run) bool {
+			return stored.ID == scrapeRun.ID
+		}); i >= 0 {
+			r.runs[i] = saved
+		}
+		return nil
+	}
+	saved.ID = int64(len(r.runs) + 1)
+	r.runs = append(r.runs, saved)
+	scrapeRun.ID = saved.ID
+	return nil
+}
@@ -0,0 +1,205 @@
+package runrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/run"
+	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	_ RunRepo = (*runRepoImpl)(nil)
+)
+
+type RunPo struct {
+	ID             int64         `db:"id"`
+	StartedAt      database.Time `db:"started_at"`
+	FinishedAt     database.Time `db:"finished_at"`
+	Status         string        `db:"status"`
+	Sources        string        `db:"sources"`
+	Professions    string        `db:"professions"`
+	PagesRequested int64         `db:"pages_requested"`
+	PagesVisited   int64         `db:"pages_visited"`
+	JobsNew        int64         `db:"jobs_new"`
+	JobsUpdated    int64         `db:"jobs_updated"`
+	JobsUnchanged  int64         `db:"jobs_unchanged"`
+	JobsSkipped    int64         `db:"jobs_skipped"`
+	JobsClosed     int64         `db:"jobs_closed"`
+	Errors         int64         `db:"errors"`
+	ErrorMessages  string        `db:"error_messages"`
+	Error          string        `db:"error"`
+}
+
+type RunRepo interface {
+	// FindLatest returns at most limit runs, newest first.
+	FindLatest(ctx context.Context, limit int64) ([]*run.Run, error)
+	// FindByID returns the run with the given id, or nil if there is none.
+	FindByID(ctx context.Context, id int64) (*run.Run, error)
+	// FindJobLinks returns the links of the jobs seen by the run.
+	FindJobLinks(ctx context.Context, id int64) ([]string, error)
+	// Save inserts the run when its ID is 0 and updates it otherwise.
+	Save(ctx context.Context, r *run.Run) error
+}
+
+type runRepoImpl struct {
+	db *database.DB
+}
+
+func (p *RunPo) ToRun() (*run.Run, error) {
+	r := &run.Run{
+		ID:             p.ID,
+		StartedAt:      time.Time(p.StartedAt),
+		FinishedAt:     time.Time(p.FinishedAt),
+		Status:         run.Status(p.Status),
+		PagesRequested: int(p.PagesRequested),
+		PagesVisited:   int(p.PagesVisited),
+		JobsNew:        int(p.JobsNew),
+		JobsUpdated:    int(p.JobsUpdated),
+		JobsUnchanged:  int(p.JobsUnchanged),
+		JobsSkipped:    int(p.JobsSkipped),
+		JobsClosed:     int(p.JobsClosed),
+		Errors:         int(p.Errors),
+		Error:          p.Error,
+	}
+	if err := json.Unmarshal([]byte(p.Sources), &r.Sources); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sources: %w", err)
+	}
+	if err := json.Unmarshal([]byte(p.Professions), &r.Professions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal professions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(p.ErrorMessages), &r.ErrorMessages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal error messages: %w", err)
+	}
+	return r, nil
+}
+
+func NewRunRepo() *runRepoImpl {
+	db, err := database.Connect()
+	util.PanicError(err)
+	return &runRepoImpl{db: db}
+}
+
+// NewRunRepoWithDB returns a repo over db instead of the shared database.
+func NewRunRepoWithDB(db *database.DB) *runRepoImpl {
+	return &runRepoImpl{db: db}
+}
+
+func (r *runRepoImpl) FindLatest(ctx context.Context, limit int64) ([]*run.Run, error) {
+	sql, args, err := sq.Select("*").
+		From("scrape_runs").
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*RunPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select scrape_runs: %w", err)
+	}
+	runs := make([]*run.Run, 0, len(pos))
+	for _, po := range pos {
+		scrapeRun, err := po.ToRun()
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, scrapeRun)
+	}
+	return runs, nil
+}
+
+func (r *runRepoImpl) FindByID(ctx context.Context, id int64) (*run.Run, error) {
+	sql, args, err := sq.Select("*").
+		From("scrape_runs").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*RunPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select scrape_run: %w", err)
+	}
+	if len(pos) == 0 {
+		return nil, nil
+	}
+	return pos[0].ToRun()
+}
+
+func (r *runRepoImpl) FindJobLinks(ctx context.Context, id int64) ([]string, error) {
+	sql, args, err := sq.Select("j.link").
+		From("jobs_scrape_runs AS jr").
+		Join("jobs AS j ON jr.job_id = j.id").
+		Where(sq.Eq{"jr.run_id": id}).
+		OrderBy("j.id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	links := []string{}
+	if err := r.db.SelectContext(ctx, &links, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select jobs_scrape_runs: %w", err)
+	}
+	return links, nil
+}
+
+func (r *runRepoImpl) Save(ctx context.Context, scrapeRun *run.Run) error {
+	sources, err := json.Marshal(scrapeRun.Sources)
+	if err != nil {
+		return err
+	}
+	professions, err := json.Marshal(scrapeRun.Professions)
+	if err != nil {
+		return err
+	}
+	errorMessages, err := json.Marshal(scrapeRun.ErrorMessages)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{
+		"started_at":      database.Time(scrapeRun.StartedAt),
+		"finished_at":     database.Time(scrapeRun.FinishedAt),
+		"status":          scrapeRun.Status,
+		"sources":         string(sources),
+		"professions":     string(professions),
+		"pages_requested": scrapeRun.PagesRequested,
+		"pages_visited":   scrapeRun.PagesVisited,
+		"jobs_new":        scrapeRun.JobsNew,
+		"jobs_updated":    scrapeRun.JobsUpdated,
+		"jobs_unchanged":  scrapeRun.JobsUnchanged,
+		"jobs_skipped":    scrapeRun.JobsSkipped,
+		"jobs_closed":     scrapeRun.JobsClosed,
+		"errors":          scrapeRun.Errors,
+		"error_messages":  string(errorMessages),
+		"error":           scrapeRun.Error,
+	}
+	if scrapeRun.ID != 0 {
+		sql, args, err := sq.Update("scrape_runs").
+			SetMap(values).
+			Where(sq.Eq{"id": scrapeRun.ID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to update scrape_run: %w", err)
+		}
+		return nil
+	}
+	sql, args, err := sq.Insert("scrape_runs").
+		SetMap(values).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if err := r.db.GetContext(ctx, &scrapeRun.ID, sql, args...); err != nil {
+		return fmt.Errorf("failed to insert scrape_run: %w", err)
+	}
+	return nil
+}
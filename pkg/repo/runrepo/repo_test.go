@@ -0,0 +1,151 @@
+package runrepo
+
+import (
+	"cake-scraper/pkg/database/dbtest"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/run"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RunRepoTestSuite is the contract every RunRepo implementation must meet. It
+// runs against a new repo of one implementation per test, with a job repo
+// recording the jobs its runs see.
+type RunRepoTestSuite struct {
+	suite.Suite
+	newRepos func(t testing.TB) (RunRepo, jobrepo.JobRepo)
+	repo     RunRepo
+	jobRepo  jobrepo.JobRepo
+}
+
+var ctx = context.Background()
+
+func (s *RunRepoTestSuite) SetupTest() {
+	s.repo, s.jobRepo = s.newRepos(s.T())
+}
+
+func (s *RunRepoTestSuite) newRun(startedAt time.Time) *run.Run {
+	r := run.New(startedAt)
+	r.Sources = []string{"cake"}
+	r.Professions = []string{"it_back-end-engineer"}
+	return r
+}
+
+func (s *RunRepoTestSuite) TestSave() {
+	// Given
+	r := s.newRun(time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.repo.Save(ctx, r))
+	s.NotZero(r.ID)
+
+	// When
+	r.FinishedAt = time.Date(2024, 10, 1, 8, 5, 0, 0, time.UTC)
+	r.Status = run.Failed
+	r.PagesVisited = 3
+	r.JobsNew = 2
+	r.Errors = 1
+	r.ErrorMessages = []string{"https://www.cake.me/jobs: timeout"}
+	r.Error = "too many errors"
+	err := s.repo.Save(ctx, r)
+
+	// Then
+	s.Require().NoError(err)
+	stored, err := s.repo.FindByID(ctx, r.ID)
+	s.Require().NoError(err)
+	s.Equal(r, stored)
+}
+
+func (s *RunRepoTestSuite) TestFindByID_NotFound() {
+	// When
+	r, err := s.repo.FindByID(ctx, 1)
+
+	// Then
+	s.Require().NoError(err)
+	s.Nil(r)
+}
+
+func (s *RunRepoTestSuite) TestFindLatest() {
+	// Given
+	startedAt := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		s.Require().NoError(s.repo.Save(ctx, s.newRun(startedAt.Add(time.Duration(i)*time.Hour))))
+	}
+
+	// When
+	runs, err := s.repo.FindLatest(ctx, 2)
+
+	// Then
+	s.Require().NoError(err)
+	s.Require().Len(runs, 2)
+	s.Equal(startedAt.Add(2*time.Hour), runs[0].StartedAt)
+	s.Equal(startedAt.Add(time.Hour), runs[1].StartedAt)
+}
+
+func (s *RunRepoTestSuite) TestFindJobLinks() {
+	// Given
+	r := s.newRun(time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.repo.Save(ctx, r))
+	other := s.newRun(time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.repo.Save(ctx, other))
+	for link, runID := range map[string]int64{
+		"https://www.cake.me/companies/acme/jobs/backend-engineer": r.ID,
+		"https://www.cake.me/companies/acme/jobs/sre":              other.ID,
+	} {
+		j := job.New()
+		j.Source = "cake"
+		j.Link = link
+		j.Company = "Acme"
+		_, err := s.jobRepo.Save(ctx, j, runID)
+		s.Require().NoError(err)
+	}
+
+	// When
+	links, err := s.repo.FindJobLinks(ctx, r.ID)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal([]string{"https://www.cake.me/companies/acme/jobs/backend-engineer"}, links)
+}
+
+func (s *RunRepoTestSuite) TestCanceledContext() {
+	// Given
+	canceled, cancel := context.WithCancel(ctx)
+
+	// When
+	cancel()
+	saveErr := s.repo.Save(canceled, s.newRun(time.Now()))
+	_, latestErr := s.repo.FindLatest(canceled, 1)
+	_, findErr := s.repo.FindByID(canceled, 1)
+	_, linksErr := s.repo.FindJobLinks(canceled, 1)
+
+	// Then
+	s.ErrorIs(saveErr, context.Canceled)
+	s.ErrorIs(latestErr, context.Canceled)
+	s.ErrorIs(findErr, context.Canceled)
+	s.ErrorIs(linksErr, context.Canceled)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestRunRepoTestSuite(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		suite.Run(t, &RunRepoTestSuite{newRepos: func(t testing.TB) (RunRepo, jobrepo.JobRepo) {
+			jobRepo := jobrepo.NewMemoryJobRepo()
+			return NewMemoryRunRepo(jobRepo.RunLinks), jobRepo
+		}})
+	})
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &RunRepoTestSuite{newRepos: func(t testing.TB) (RunRepo, jobrepo.JobRepo) {
+				db := backend.Open(t)
+				return NewRunRepoWithDB(db), jobrepo.NewJobRepoWithDB(db)
+			}})
+		})
+	}
+}
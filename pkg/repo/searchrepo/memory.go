@@ -0,0 +1,129 @@
+package searchrepo
+
+import (
+	"cake-scraper/pkg/search"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ SearchRepo = (*memorySearchRepo)(nil)
+)
+
+// memorySearchRepo keeps saved searches in memory, in the order they were
+// saved.
+type memorySearchRepo struct {
+	mu       sync.RWMutex
+	searches []*search.Search
+	lastID   int64
+}
+
+func NewMemorySearchRepo() *memorySearchRepo {
+	return &memorySearchRepo{}
+}
+
+func cloneSearch(s *search.Search) *search.Search {
+	clone := *s
+	clone.Conditions = s.Conditions.Clone()
+	return &clone
+}
+
+// byID returns the index of the stored search with the given id, or -1. r.mu
+// must be held.
+func (r *memorySearchRepo) byID(id int64) int {
+	return slices.IndexFunc(r.searches, func(s *search.Search) bool {
+		return s.ID == id
+	})
+}
+
+// now is the current time at the precision the database stores.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func (r *memorySearchRepo) FindAll(ctx context.Context) ([]*search.Search, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	searches := make([]*search.Search, 0, len(r.searches))
+	for _, s := range r.searches {
+		searches = append(searches, cloneSearch(s))
+	}
+	r.mu.RUnlock()
+	slices.SortStableFunc(searches, func(a, b *search.Search) int {
+		return cmp.Or(
+			strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return searches, nil
+}
+
+func (r *memorySearchRepo) FindByID(ctx context.Context, id int64) (*search.Search, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if i := r.byID(id); i >= 0 {
+		return cloneSearch(r.searches[i]), nil
+	}
+	return nil, nil
+}
+
+func (r *memorySearchRepo) Save(ctx context.Context, s *search.Search) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.ID != 0 {
+		if i := r.byID(s.ID); i >= 0 {
+			stored := r.searches[i]
+			stored.Name = s.Name
+			stored.Conditions = s.Conditions.Clone()
+			stored.UpdatedAt = now()
+		}
+		return nil
+	}
+	r.lastID++
+	saved := cloneSearch(s)
+	saved.ID = r.lastID
+	saved.CreatedAt = now()
+	saved.UpdatedAt = saved.CreatedAt
+	saved.LastViewedAt = time.Time{}
+	r.searches = append(r.searches, saved)
+	s.ID = saved.ID
+	return nil
+}
+
+func (r *memorySearchRepo) MarkViewed(ctx context.Context, id int64, t time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.byID(id); i >= 0 {
+		r.searches[i].LastViewedAt = t.UTC().Truncate(time.Second)
+	}
+	return nil
+}
+
+func (r *memorySearchRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.byID(id)
+	if i < 0 {
+		return false, nil
+	}
+	r.searches = slices.Delete(r.searches, i, i+1)
+	return true, nil
+}
@@ -0,0 +1,182 @@
+package searchrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/search"
+	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	_ SearchRepo = (*searchRepoImpl)(nil)
+)
+
+type SearchPo struct {
+	ID           int64         `db:"id"`
+	Name         string        `db:"name"`
+	Conditions   string        `db:"conditions"`
+	CreatedAt    database.Time `db:"created_at"`
+	UpdatedAt    database.Time `db:"updated_at"`
+	LastViewedAt database.Time `db:"last_viewed_at"`
+}
+
+type SearchRepo interface {
+	// FindAll returns the saved searches ordered by name.
+	FindAll(ctx context.Context) ([]*search.Search, error)
+	// FindByID returns the saved search with the given id, or nil if there is
+	// none.
+	FindByID(ctx context.Context, id int64) (*search.Search, error)
+	// Save inserts the search when its ID is 0 and updates its name and
+	// conditions otherwise.
+	Save(ctx context.Context, s *search.Search) error
+	// MarkViewed records that the jobs of the search were checked at t.
+	MarkViewed(ctx context.Context, id int64, t time.Time) error
+	// Delete deletes the saved search with the given id and reports whether
+	// there was one.
+	Delete(ctx context.Context, id int64) (bool, error)
+}
+
+type searchRepoImpl struct {
+	db *database.DB
+}
+
+func (p *SearchPo) ToSearch() (*search.Search, error) {
+	s := &search.Search{
+		ID:           p.ID,
+		Name:         p.Name,
+		CreatedAt:    time.Time(p.CreatedAt),
+		UpdatedAt:    time.Time(p.UpdatedAt),
+		LastViewedAt: time.Time(p.LastViewedAt),
+	}
+	if err := json.Unmarshal([]byte(p.Conditions), &s.Conditions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conditions of saved search %d: %w", p.ID, err)
+	}
+	return s, nil
+}
+
+func NewSearchRepo() *searchRepoImpl {
+	db, err := database.Connect()
+	util.PanicError(err)
+	return &searchRepoImpl{db: db}
+}
+
+// NewSearchRepoWithDB returns a repo over db instead of the shared database.
+func NewSearchRepoWithDB(db *database.DB) *searchRepoImpl {
+	return &searchRepoImpl{db: db}
+}
+
+func (r *searchRepoImpl) FindAll(ctx context.Context) ([]*search.Search, error) {
+	sql, args, err := sq.Select("*").
+		From("saved_searches").
+		OrderBy("LOWER(name)", "id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*SearchPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select saved_searches: %w", err)
+	}
+	searches := make([]*search.Search, 0, len(pos))
+	for _, po := range pos {
+		s, err := po.ToSearch()
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, s)
+	}
+	return searches, nil
+}
+
+func (r *searchRepoImpl) FindByID(ctx context.Context, id int64) (*search.Search, error) {
+	sql, args, err := sq.Select("*").
+		From("saved_searches").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var pos []*SearchPo
+	if err := r.db.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return nil, fmt.Errorf("failed to select saved_search: %w", err)
+	}
+	if len(pos) == 0 {
+		return nil, nil
+	}
+	return pos[0].ToSearch()
+}
+
+func (r *searchRepoImpl) Save(ctx context.Context, s *search.Search) error {
+	conditions, err := json.Marshal(s.Conditions)
+	if err != nil {
+		return err
+	}
+	if s.ID != 0 {
+		sql, args, err := sq.Update("saved_searches").
+			SetMap(map[string]interface{}{
+				"name":       s.Name,
+				"conditions": string(conditions),
+				"updated_at": sq.Expr("CURRENT_TIMESTAMP"),
+			}).
+			Where(sq.Eq{"id": s.ID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
+			return fmt.Errorf("failed to update saved_search: %w", err)
+		}
+		return nil
+	}
+	sql, args, err := sq.Insert("saved_searches").
+		SetMap(map[string]interface{}{
+			"name":       s.Name,
+			"conditions": string(conditions),
+		}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if err := r.db.GetContext(ctx, &s.ID, sql, args...); err != nil {
+		return fmt.Errorf("failed to insert saved_search: %w", err)
+	}
+	return nil
+}
+
+func (r *searchRepoImpl) MarkViewed(ctx context.Context, id int64, t time.Time) error {
+	sql, args, err := sq.Update("saved_searches").
+		Set("last_viewed_at", database.Time(t)).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, sql, args...); err != nil {
+		return fmt.Errorf("failed to update saved_search: %w", err)
+	}
+	return nil
+}
+
+func (r *searchRepoImpl) Delete(ctx context.Context, id int64) (bool, error) {
+	sql, args, err := sq.Delete("saved_searches").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+	result, err := r.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved_search: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
@@ -0,0 +1,144 @@
+package searchrepo
+
+import (
+	"cake-scraper/pkg/database/dbtest"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/search"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SearchRepoTestSuite is the contract every SearchRepo implementation must
+// meet. It runs against a new repo of one implementation per test.
+type SearchRepoTestSuite struct {
+	suite.Suite
+	newRepo func(t testing.TB) SearchRepo
+	repo    SearchRepo
+}
+
+var ctx = context.Background()
+
+func (s *SearchRepoTestSuite) SetupTest() {
+	s.repo = s.newRepo(s.T())
+}
+
+// requireSameConditions asserts that both conditions serialize the same.
+func (s *SearchRepoTestSuite) requireSameConditions(want, got jobrepo.Conditions) {
+	wantJSON, err := json.Marshal(want)
+	s.Require().NoError(err)
+	gotJSON, err := json.Marshal(got)
+	s.Require().NoError(err)
+	s.JSONEq(string(wantJSON), string(gotJSON))
+}
+
+func (s *SearchRepoTestSuite) TestSave() {
+	// Given
+	conditions := jobrepo.NewConditions().
+		Active().
+		MainCategory("Software").
+		Remote(job.FullRemote).
+		Salary(1_200_000, 0)
+	saved := search.New("Remote backend", conditions)
+	s.Require().NoError(s.repo.Save(ctx, saved))
+	s.NotZero(saved.ID)
+
+	// When
+	saved.Name = "Backend"
+	saved.Conditions = conditions.Tags("Go")
+	err := s.repo.Save(ctx, saved)
+
+	// Then
+	s.Require().NoError(err)
+	stored, err := s.repo.FindByID(ctx, saved.ID)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal("Backend", stored.Name)
+	s.requireSameConditions(saved.Conditions, stored.Conditions)
+	s.False(stored.CreatedAt.IsZero())
+	s.False(stored.UpdatedAt.IsZero())
+	s.True(stored.LastViewedAt.IsZero())
+}
+
+func (s *SearchRepoTestSuite) TestFindByID_NotFound() {
+	// When
+	stored, err := s.repo.FindByID(ctx, 1)
+
+	// Then
+	s.Require().NoError(err)
+	s.Nil(stored)
+}
+
+func (s *SearchRepoTestSuite) TestFindAll() {
+	// Given
+	s.Require().NoError(s.repo.Save(ctx, search.New("taipei", jobrepo.NewConditions())))
+	s.Require().NoError(s.repo.Save(ctx, search.New("Backend", jobrepo.NewConditions())))
+
+	// When
+	searches, err := s.repo.FindAll(ctx)
+
+	// Then
+	s.Require().NoError(err)
+	s.Require().Len(searches, 2)
+	s.Equal("Backend", searches[0].Name)
+	s.Equal("taipei", searches[1].Name)
+}
+
+func (s *SearchRepoTestSuite) TestMarkViewed() {
+	// Given
+	saved := search.New("Backend", jobrepo.NewConditions())
+	s.Require().NoError(s.repo.Save(ctx, saved))
+	viewedAt := time.Date(2024, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	// When
+	err := s.repo.MarkViewed(ctx, saved.ID, viewedAt)
+
+	// Then
+	s.Require().NoError(err)
+	stored, err := s.repo.FindByID(ctx, saved.ID)
+	s.Require().NoError(err)
+	s.Equal(viewedAt, stored.LastViewedAt)
+}
+
+func (s *SearchRepoTestSuite) TestDelete() {
+	// Given
+	saved := search.New("Backend", jobrepo.NewConditions())
+	s.Require().NoError(s.repo.Save(ctx, saved))
+
+	// When
+	deleted, err := s.repo.Delete(ctx, saved.ID)
+	s.Require().NoError(err)
+	deletedAgain, err := s.repo.Delete(ctx, saved.ID)
+
+	// Then
+	s.Require().NoError(err)
+	s.True(deleted)
+	s.False(deletedAgain)
+	stored, err := s.repo.FindByID(ctx, saved.ID)
+	s.Require().NoError(err)
+	s.Nil(stored)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestSearchRepoTestSuite(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		suite.Run(t, &SearchRepoTestSuite{newRepo: func(t testing.TB) SearchRepo {
+			return NewMemorySearchRepo()
+		}})
+	})
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &SearchRepoTestSuite{newRepo: func(t testing.TB) SearchRepo {
+				return NewSearchRepoWithDB(backend.Open(t))
+			}})
+		})
+	}
+}
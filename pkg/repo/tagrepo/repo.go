@@ -0,0 +1,134 @@
+package tagrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/tag"
+	"cake-scraper/pkg/util"
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ TagRepo = (*tagRepoImpl)(nil)
+)
+
+type TagPo struct {
+	ID             int64  `db:"id"`
+	Tag            string `db:"tag"`
+	CanonicalTagID *int64 `db:"canonical_tag_id"`
+}
+
+type TagRepo interface {
+	// Canonicalize links every stored tag to its canonical tag in the current
+	// dictionary and returns how many tags were relinked.
+	Canonicalize(ctx context.Context) (int, error)
+}
+
+type tagRepoImpl struct {
+	db *database.DB
+}
+
+func NewTagRepo() *tagRepoImpl {
+	db, err := database.Connect()
+	util.PanicError(err)
+	return &tagRepoImpl{db: db}
+}
+
+// NewTagRepoWithDB returns a repo over db instead of the shared database.
+func NewTagRepoWithDB(db *database.DB) *tagRepoImpl {
+	return &tagRepoImpl{db: db}
+}
+
+// Save upserts the tag linked to its canonical tag, which is saved too, and
+// returns its id.
+func Save(ctx context.Context, tx sqlx.QueryerContext, name string) (int64, error) {
+	canonicalID, err := saveCanonical(ctx, tx, name)
+	if err != nil {
+		return 0, err
+	}
+	return upsert(ctx, tx, name, canonicalID)
+}
+
+// saveCanonical saves the canonical tag of name and returns its id, or nil if
+// name is canonical.
+func saveCanonical(ctx context.Context, tx sqlx.QueryerContext, name string) (*int64, error) {
+	canonical := tag.Canonical(name)
+	if canonical == name {
+		return nil, nil
+	}
+	id, err := upsert(ctx, tx, canonical, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func upsert(ctx context.Context, tx sqlx.QueryerContext, name string, canonicalID *int64) (int64, error) {
+	sql, args, err := sq.Insert("tags").
+		Columns("tag", "canonical_tag_id").
+		Values(name, canonicalID).
+		Suffix("ON CONFLICT(tag) DO UPDATE SET canonical_tag_id = EXCLUDED.canonical_tag_id").
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if err := sqlx.GetContext(ctx, tx, &id, sql, args...); err != nil {
+		return 0, fmt.Errorf("failed to insert tag: %w", err)
+	}
+	return id, nil
+}
+
+func (r *tagRepoImpl) Canonicalize(ctx context.Context) (changed int, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	sql, args, err := sq.Select("id", "tag", "canonical_tag_id").
+		From("tags").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var pos []*TagPo
+	if err := tx.SelectContext(ctx, &pos, sql, args...); err != nil {
+		return 0, fmt.Errorf("failed to select tags: %w", err)
+	}
+	for _, po := range pos {
+		canonicalID, err := saveCanonical(ctx, tx, po.Tag)
+		if err != nil {
+			return 0, err
+		}
+		if sameID(canonicalID, po.CanonicalTagID) {
+			continue
+		}
+		sql, args, err := sq.Update("tags").
+			Set("canonical_tag_id", canonicalID).
+			Where(sq.Eq{"id": po.ID}).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("failed to update tag: %w", err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func sameID(a, b *int64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
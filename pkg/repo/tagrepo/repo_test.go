@@ -0,0 +1,119 @@
+package tagrepo
+
+import (
+	"cake-scraper/pkg/database"
+	"cake-scraper/pkg/database/dbtest"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TagRepoTestSuite struct {
+	suite.Suite
+	open func(t testing.TB) *database.DB
+	db   *database.DB
+	repo *tagRepoImpl
+}
+
+func (s *TagRepoTestSuite) SetupTest() {
+	s.db = s.open(s.T())
+	s.repo = NewTagRepoWithDB(s.db)
+}
+
+// insert stores a tag linked to canonicalID as is, the way a previous
+// dictionary may have left it.
+func (s *TagRepoTestSuite) insert(name string, canonicalID *int64) int64 {
+	var id int64
+	s.Require().NoError(s.db.Get(&id, "INSERT INTO tags (tag, canonical_tag_id) VALUES (?, ?) RETURNING id", name, canonicalID))
+	return id
+}
+
+// canonicals returns the canonical tag of each stored tag, or "" if it is
+// canonical.
+func (s *TagRepoTestSuite) canonicals() map[string]string {
+	var rows []struct {
+		Tag       string  `db:"tag"`
+		Canonical *string `db:"canonical"`
+	}
+	s.Require().NoError(s.db.Select(&rows, "SELECT t.tag, c.tag AS canonical FROM tags t LEFT JOIN tags c ON t.canonical_tag_id = c.id"))
+	canonicals := map[string]string{}
+	for _, row := range rows {
+		canonicals[row.Tag] = ""
+		if row.Canonical != nil {
+			canonicals[row.Tag] = *row.Canonical
+		}
+	}
+	return canonicals
+}
+
+func (s *TagRepoTestSuite) TestCanonicalize() {
+	// Given
+	goID := s.insert("Go", nil)
+	s.insert("Rust", nil)
+	s.insert("golang", &goID)
+	// Saved before the dictionary knew the alias.
+	s.insert("k8s", nil)
+	// Saved while the alias meant another tag.
+	s.insert("js", &goID)
+	// Saved while the tag was an alias.
+	s.insert("Elixir", &goID)
+
+	// When
+	changed, err := s.repo.Canonicalize(context.Background())
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(3, changed)
+	s.Equal(map[string]string{
+		"Go":         "",
+		"Rust":       "",
+		"golang":     "Go",
+		"k8s":        "Kubernetes",
+		"Kubernetes": "",
+		"js":         "JavaScript",
+		"JavaScript": "",
+		"Elixir":     "",
+	}, s.canonicals())
+}
+
+func (s *TagRepoTestSuite) TestCanonicalize_Unchanged() {
+	// Given
+	goID := s.insert("Go", nil)
+	s.insert("golang", &goID)
+
+	// When
+	changed, err := s.repo.Canonicalize(context.Background())
+
+	// Then
+	s.Require().NoError(err)
+	s.Zero(changed)
+	s.Equal(map[string]string{"Go": "", "golang": "Go"}, s.canonicals())
+}
+
+func (s *TagRepoTestSuite) TestCanonicalize_CanceledContext() {
+	// Given
+	s.insert("golang", nil)
+	canceled, cancel := context.WithCancel(context.Background())
+
+	// When
+	cancel()
+	_, err := s.repo.Canonicalize(canceled)
+
+	// Then
+	s.ErrorIs(err, context.Canceled)
+	s.Equal(map[string]string{"golang": ""}, s.canonicals())
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
+
+func TestTagRepoTestSuite(t *testing.T) {
+	for _, backend := range dbtest.Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			suite.Run(t, &TagRepoTestSuite{open: backend.Open})
+		})
+	}
+}
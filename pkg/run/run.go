@@ -0,0 +1,43 @@
+package run
+
+import "time"
+
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Canceled  Status = "canceled"
+	Failed    Status = "failed"
+)
+
+// Run is the record of one scrape run.
+type Run struct {
+	ID             int64
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Status         Status
+	Sources        []string
+	Professions    []string
+	PagesRequested int
+	PagesVisited   int
+	JobsNew        int
+	JobsUpdated    int
+	JobsUnchanged  int
+	JobsSkipped    int
+	JobsClosed     int
+	Errors         int
+	ErrorMessages  []string
+	// Error is the reason the run stopped early, if it did.
+	Error string
+}
+
+func New(startedAt time.Time) *Run {
+	return &Run{
+		StartedAt:     startedAt,
+		Status:        Running,
+		Sources:       []string{},
+		Professions:   []string{},
+		ErrorMessages: []string{},
+	}
+}
@@ -0,0 +1,469 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/htmlparser"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	BackendDeveloper  Profession = "it_back-end-engineer"
+	DataEngineer      Profession = "it_data-engineer"
+	FrontendDeveloper Profession = "it_front-end-engineer"
+	CakeSourceName               = "cake"
+	DefaultBaseURL               = "https://www.cake.me"
+)
+
+var (
+	_                     IncrementalSource = (*cakeSource)(nil)
+	_                     CompanySource     = (*cakeSource)(nil)
+	errMissingTitle                         = errors.New("missing job title")
+	errMissingCompanyName                   = errors.New("missing company name")
+	// companyPathRegex captures the company slug of the path of a company or
+	// job page.
+	companyPathRegex = regexp.MustCompile(`^/companies/([^/]+)`)
+	yearRegex        = regexp.MustCompile(`\b\d{4}\b`)
+)
+
+type Profession string
+
+func (p Profession) String() string {
+	return string(p)
+}
+
+func jobListUrlRegex(baseURL string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(baseURL) + `/jobs.*$`)
+}
+
+func jobDetailUrlRegex(baseURL string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(baseURL) + `/companies/(.*)/jobs/(.*)$`)
+}
+
+func companyUrlRegex(baseURL string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(baseURL) + `/companies/[^/]+$`)
+}
+
+func buildCompanyUrl(baseURL, slug string) string {
+	return fmt.Sprintf("%s/companies/%s", baseURL, url.PathEscape(slug))
+}
+
+func buildJobListUrl(baseURL string, profession Profession, page int) string {
+	return fmt.Sprintf("%s/jobs?location_list%%5B0%%5D=Taiwan&profession%%5B0%%5D=%s&order=latest&page=%d", baseURL, profession, page)
+}
+
+// listingCrawl tracks the list pages of a profession, to tell whether the
+// crawl saw every job it lists.
+type listingCrawl struct {
+	mu    sync.Mutex
+	links []string
+	// listed holds the pages that listed jobs.
+	listed map[int]bool
+	// end is the first page without jobs, or 0 if none was reached.
+	end    int
+	failed bool
+}
+
+func (c *listingCrawl) pageListed(page int, links []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.links = append(c.links, links...)
+	c.listed[page] = true
+}
+
+func (c *listingCrawl) pageEmpty(page int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.end == 0 || page < c.end {
+		c.end = page
+	}
+}
+
+func (c *listingCrawl) pageFailed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed = true
+}
+
+// complete reports whether every page up to the end of the listing was
+// crawled. A crawl that stops at MaxPage before reaching a page without jobs
+// is not complete, as the listing may go on past it. A listing without jobs
+// is never complete, as it more likely means the crawl broke than that every
+// job closed.
+func (c *listingCrawl) complete() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.end <= 1 || c.failed {
+		return false
+	}
+	for page := 1; page < c.end; page++ {
+		if !c.listed[page] {
+			return false
+		}
+	}
+	return true
+}
+
+type cakeSource struct {
+	Professions []Profession
+	MaxPage     int
+	baseURL     string
+	logger      *slog.Logger
+}
+
+// NewCakeSource creates a source that scrapes the latest Cake.me jobs of the
+// given professions, up to MaxPage list pages each.
+func NewCakeSource(MaxPage int, Professions ...Profession) *cakeSource {
+	return newCakeSource(DefaultBaseURL, MaxPage, Professions...)
+}
+
+func newCakeSource(baseURL string, MaxPage int, Professions ...Profession) *cakeSource {
+	return &cakeSource{
+		MaxPage:     MaxPage,
+		Professions: Professions,
+		baseURL:     baseURL,
+		logger:      slog.Default().WithGroup("cake"),
+	}
+}
+
+func (s *cakeSource) Name() string {
+	return CakeSourceName
+}
+
+func (s *cakeSource) Scrape(ctx context.Context, report *Report, yield func(j *job.Job)) error {
+	return s.scrape(ctx, report, nil, 0, yield)
+}
+
+func (s *cakeSource) ScrapeIncremental(ctx context.Context, report *Report, index LinkIndex, freshness time.Duration, yield func(j *job.Job)) error {
+	return s.scrape(ctx, report, index, freshness, yield)
+}
+
+// scrape visits the list pages of every profession and the jobs they link to.
+// Without an index all list pages are requested at once. With one, the pages
+// of a profession are walked in order until a page only lists known links.
+// The links of every profession whose list pages were all crawled are
+// reported, so that jobs no longer listed can be closed.
+func (s *cakeSource) scrape(ctx context.Context, report *Report, index LinkIndex, freshness time.Duration, yield func(j *job.Job)) error {
+	report.Requested(len(s.Professions)*s.MaxPage, util.Map(s.Professions, Profession.String)...)
+	crawls := map[Profession]*listingCrawl{}
+	for _, profession := range s.Professions {
+		crawls[profession] = &listingCrawl{listed: map[int]bool{}}
+	}
+	crawlOf := func(r *colly.Request) (*listingCrawl, int) {
+		profession, _ := r.Ctx.GetAny("profession").(Profession)
+		page, _ := r.Ctx.GetAny("page").(int)
+		return crawls[profession], page
+	}
+	filters := colly.URLFilters(jobDetailUrlRegex(s.baseURL), jobListUrlRegex(s.baseURL))
+	linkCollector := NewCollector(ctx, filters)
+	detailCollector := NewCollector(ctx, filters)
+	visitList := func(profession Profession, page int) error {
+		listCtx := colly.NewContext()
+		listCtx.Put("profession", profession)
+		listCtx.Put("page", page)
+		return linkCollector.Request("GET", buildJobListUrl(s.baseURL, profession, page), nil, listCtx, nil)
+	}
+	linkCollector.OnResponse(func(r *colly.Response) {
+		report.PageVisited()
+	})
+	linkCollector.OnHTML("div[class^='JobSearchHits_list__']", func(e *colly.HTMLElement) {
+		hrefs := e.ChildAttrs("a[class^='JobSearchItem_jobTitle__']", "href")
+		hrefs = util.Filter(hrefs, func(href string) bool {
+			return href != ""
+		})
+		links := util.Unique(util.Map(hrefs, func(href string) string {
+			return e.Request.AbsoluteURL(href)
+		}))
+		if len(links) > 0 {
+			crawl, page := crawlOf(e.Request)
+			crawl.pageListed(page, links)
+			e.Request.Ctx.Put("listed", true)
+		}
+		lastFetched := map[string]time.Time{}
+		if index != nil {
+			var err error
+			if lastFetched, err = index.LastFetched(ctx, links); err != nil {
+				s.logger.Error("failed to look up known links", "URL", e.Request.URL, "Error", err)
+				lastFetched = map[string]time.Time{}
+			}
+		}
+		for _, link := range links {
+			if ctx.Err() != nil {
+				return
+			}
+			if fetchedAt, ok := lastFetched[link]; ok && time.Since(fetchedAt) < freshness {
+				report.JobSkipped(link)
+				continue
+			}
+			if err := detailCollector.Visit(link); err != nil {
+				s.logger.Error("failed to visit job", "URL", link, "Error", err)
+				report.VisitFailed(link, err)
+			}
+		}
+		if index == nil || ctx.Err() != nil {
+			return
+		}
+		if len(links) == 0 {
+			s.logger.Info("reached the end of the listing", "URL", e.Request.URL)
+			return
+		}
+		if len(lastFetched) == len(links) {
+			s.logger.Info("reached known jobs", "URL", e.Request.URL)
+			return
+		}
+		profession, _ := e.Request.Ctx.GetAny("profession").(Profession)
+		page, _ := e.Request.Ctx.GetAny("page").(int)
+		if page < s.MaxPage {
+			if err := visitList(profession, page+1); err != nil {
+				s.logger.Error("failed to visit job list", "Profession", profession, "Page", page+1, "Error", err)
+				crawls[profession].pageFailed()
+				report.VisitFailed(buildJobListUrl(s.baseURL, profession, page+1), err)
+			}
+		}
+	})
+	linkCollector.OnScraped(func(r *colly.Response) {
+		if listed, _ := r.Ctx.GetAny("listed").(bool); !listed {
+			crawl, page := crawlOf(r.Request)
+			crawl.pageEmpty(page)
+		}
+	})
+	detailCollector.OnResponse(func(r *colly.Response) {
+		report.PageVisited()
+	})
+	detailCollector.OnHTML("body", func(e *colly.HTMLElement) {
+		j, err := parseCakeJob(e)
+		if err != nil {
+			s.logger.Error("failed to parse job", "URL", e.Request.URL, "Error", err)
+			report.ParseFailed(e.Request.URL.String(), err)
+			return
+		}
+		yield(j)
+	})
+	linkCollector.OnError(func(r *colly.Response, err error) {
+		crawl, page := crawlOf(r.Request)
+		if r.StatusCode == 404 {
+			crawl.pageEmpty(page)
+			return
+		}
+		crawl.pageFailed()
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("linkCollector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
+		report.HTTPError(r.Request.URL.String(), r.StatusCode, err)
+	})
+	detailCollector.OnError(func(r *colly.Response, err error) {
+		if r.StatusCode == 404 {
+			s.logger.Info("job is gone", "URL", r.Request.URL)
+			report.JobGone(r.Request.URL.String())
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("detailCollector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
+		report.HTTPError(r.Request.URL.String(), r.StatusCode, err)
+	})
+	for _, profession := range s.Professions {
+		if index != nil {
+			if err := visitList(profession, 1); err != nil {
+				return err
+			}
+			continue
+		}
+		for page := 1; page <= s.MaxPage && ctx.Err() == nil; page++ {
+			if err := visitList(profession, page); err != nil {
+				return err
+			}
+		}
+	}
+	linkCollector.Wait()
+	detailCollector.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	for profession, crawl := range crawls {
+		if crawl.complete() {
+			report.Listed(profession.String(), util.Unique(crawl.links))
+		}
+	}
+	return nil
+}
+
+func (s *cakeSource) ScrapeCompanies(ctx context.Context, report *Report, slugs []string, yield func(c *company.Company)) error {
+	collector := NewCollector(ctx, colly.URLFilters(companyUrlRegex(s.baseURL)))
+	collector.OnResponse(func(r *colly.Response) {
+		report.PageVisited()
+	})
+	collector.OnHTML("body", func(e *colly.HTMLElement) {
+		c, err := parseCakeCompany(e)
+		if err != nil {
+			s.logger.Error("failed to parse company", "URL", e.Request.URL, "Error", err)
+			report.ParseFailed(e.Request.URL.String(), err)
+			return
+		}
+		yield(c)
+	})
+	collector.OnError(func(r *colly.Response, err error) {
+		if r.StatusCode == 404 {
+			s.logger.Info("company has no profile", "URL", r.Request.URL)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("companyCollector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
+		report.HTTPError(r.Request.URL.String(), r.StatusCode, err)
+	})
+	for _, slug := range slugs {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := collector.Visit(buildCompanyUrl(s.baseURL, slug)); err != nil {
+			s.logger.Error("failed to visit company", "Slug", slug, "Error", err)
+			report.VisitFailed(buildCompanyUrl(s.baseURL, slug), err)
+		}
+	}
+	collector.Wait()
+	return nil
+}
+
+// companySlug returns the company slug of the path of a company or job page,
+// or "" if it has none.
+func companySlug(path string) string {
+	match := companyPathRegex.FindStringSubmatch(path)
+	if match == nil {
+		return ""
+	}
+	slug, err := url.PathUnescape(match[1])
+	if err != nil {
+		return match[1]
+	}
+	return slug
+}
+
+// parseCakeCompany parses a Cake.me company page. The profile is read from
+// labelled rows, so that their order does not matter.
+func parseCakeCompany(e *colly.HTMLElement) (*company.Company, error) {
+	c := company.New(companySlug(e.Request.URL.Path))
+	c.Name = e.ChildText("h1[class^='CompanyHeader_name__']")
+	if c.Name == "" {
+		return nil, errMissingCompanyName
+	}
+	e.ForEach("div[class^='CompanyInfo_item__']", func(_ int, item *colly.HTMLElement) {
+		value := item.ChildText("[class^='CompanyInfo_value__']")
+		switch item.ChildText("[class^='CompanyInfo_label__']") {
+		case "Industry":
+			c.Industry = value
+		case "Company size":
+			c.Size = strings.TrimSuffix(value, " employees")
+		case "Founded":
+			c.FoundedYear, _ = strconv.Atoi(yearRegex.FindString(value))
+		case "Location":
+			c.Location = value
+		case "Website":
+			c.Website = item.ChildAttr("a", "href")
+			if c.Website == "" {
+				c.Website = value
+			}
+		}
+	})
+	e.ForEach("div[class^='CompanyBenefits_wrapper__'] li", func(_ int, li *colly.HTMLElement) {
+		if benefit := strings.TrimSpace(li.Text); benefit != "" {
+			c.Benefits = append(c.Benefits, benefit)
+		}
+	})
+	return c, nil
+}
+
+// parseCakeJob parses a Cake.me job detail page.
+func parseCakeJob(e *colly.HTMLElement) (*job.Job, error) {
+	j := job.New()
+	j.Company = e.ChildText("div[class^='JobDescriptionLeftColumn_companyInfo__'] > a > h2")
+	j.Title = e.ChildText("h1[class^='JobDescriptionLeftColumn_title__']")
+	if j.Title == "" {
+		return nil, errMissingTitle
+	}
+	j.Link = e.Request.URL.String()
+	j.CompanySlug = companySlug(e.Request.URL.Path)
+	j.Remote = job.NoRemote
+	// Job Category
+	e.ForEach("div[class^='Breadcrumbs_wrapper__']", func(_ int, div *colly.HTMLElement) {
+		categories := div.ChildTexts("a > span")
+		switch len(categories) {
+		case 1:
+			j.MainCategory = categories[0]
+		case 2:
+			j.MainCategory = categories[0]
+			j.SubCategory = categories[1]
+		}
+	})
+	// Job Info
+	e.ForEach("div[class^='JobDescriptionRightColumn_jobInfo__'] > div[class^='JobDescriptionRightColumn_row__']", func(_ int, row *colly.HTMLElement) {
+		icons := util.Filter(strings.Split(row.ChildAttr("i", "class"), " "), func(str string) bool {
+			return str != ""
+		})
+		anchors := row.ChildTexts("a")
+		spans := row.ChildTexts("span")
+		if len(icons) == 0 {
+			// EmploymentType, Seniority, Tags
+			for _, anchor := range anchors {
+				if employmentType := job.NewEmploymentType(anchor); employmentType != job.InvalidEmploymentType {
+					j.EmploymentType = employmentType
+				} else if seniority := job.NewSeniority(anchor); seniority != job.InvalidSeniority {
+					j.Seniority = seniority
+				} else {
+					j.Tags = append(j.Tags, anchor)
+				}
+			}
+		} else {
+			// Location, NumberToHire, Experience, Salary, Remote, Tags
+			for _, icon := range icons {
+				switch icon {
+				case "fa-map-marker-alt":
+					j.Location = anchors[0]
+				case "fa-user":
+					j.NumberToHire, _ = strconv.Atoi(spans[0])
+				case "fa-business-time":
+					j.Experience = spans[0]
+				case "fa-dollar-sign":
+					j.Salary = spans[0]
+				case "fa-house":
+					j.Remote = job.NewRemote(spans[0])
+				case "fa-ellipsis-h":
+					j.Tags = append(j.Tags, anchors[0])
+				}
+			}
+		}
+	})
+	// Job Content
+	e.ForEach("div[class^='ContentSection_contentSection__']", func(_ int, section *colly.HTMLElement) {
+		contentType := section.ChildText("h3[class^='ContentSection_title__']")
+		content, _ := section.DOM.Find("div[class^='RailsHtml_container__']").Html()
+		content = htmlparser.Parse(content)
+		if content == "" {
+			return
+		}
+		switch contentType {
+		case "Interview process":
+			j.InterviewProcess = content
+		case "Job Description":
+			j.JobDescription = content
+		case "Requirements":
+			j.Requirements = content
+		}
+	})
+	return j, nil
+}
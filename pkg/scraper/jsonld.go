@@ -0,0 +1,273 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/htmlparser"
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	_                Source = (*jsonLDSource)(nil)
+	errInvalidJSONLD        = errors.New("invalid JSON-LD")
+)
+
+type jsonLDSource struct {
+	name         string
+	linkSelector string
+	startURLs    []string
+	logger       *slog.Logger
+}
+
+// NewJSONLDSource creates a source for job boards that publish schema.org
+// JobPosting data as JSON-LD. Every start URL is visited, anchors matching
+// linkSelector are followed, and every JobPosting found on a visited page is
+// yielded.
+func NewJSONLDSource(name, linkSelector string, startURLs ...string) *jsonLDSource {
+	return &jsonLDSource{
+		name:         name,
+		linkSelector: linkSelector,
+		startURLs:    startURLs,
+		logger:       slog.Default().WithGroup(name),
+	}
+}
+
+func (s *jsonLDSource) Name() string {
+	return s.name
+}
+
+func (s *jsonLDSource) Scrape(ctx context.Context, report *Report, yield func(j *job.Job)) error {
+	report.Requested(len(s.startURLs))
+	var visited sync.Map
+	c := NewCollector(ctx)
+	visit := func(link string) error {
+		if _, loaded := visited.LoadOrStore(link, struct{}{}); loaded {
+			return nil
+		}
+		return c.Visit(link)
+	}
+	c.OnResponse(func(r *colly.Response) {
+		report.PageVisited()
+	})
+	c.OnHTML(`script[type="application/ld+json"]`, func(e *colly.HTMLElement) {
+		if !gjson.Valid(e.Text) {
+			report.ParseFailed(e.Request.URL.String(), errInvalidJSONLD)
+			return
+		}
+		for _, posting := range findJobPostings(gjson.Parse(e.Text)) {
+			j := parseJobPosting(posting, e.Request.URL.String())
+			if j.Title == "" {
+				report.ParseFailed(e.Request.URL.String(), errMissingTitle)
+				continue
+			}
+			yield(j)
+		}
+	})
+	if s.linkSelector != "" {
+		c.OnHTML(s.linkSelector, func(e *colly.HTMLElement) {
+			link := e.Request.AbsoluteURL(e.Attr("href"))
+			if link == "" || ctx.Err() != nil {
+				return
+			}
+			if err := visit(link); err != nil {
+				s.logger.Error("failed to visit link", "URL", link, "Error", err)
+				report.VisitFailed(link, err)
+			}
+		})
+	}
+	c.OnError(func(r *colly.Response, err error) {
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("collector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
+		report.HTTPError(r.Request.URL.String(), r.StatusCode, err)
+	})
+	for _, link := range s.startURLs {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := visit(link); err != nil {
+			return err
+		}
+	}
+	c.Wait()
+	return nil
+}
+
+// findJobPostings returns every JobPosting node in a JSON-LD document,
+// including those nested in arrays and @graph.
+func findJobPostings(node gjson.Result) []gjson.Result {
+	var postings []gjson.Result
+	switch {
+	case node.IsArray():
+		for _, child := range node.Array() {
+			postings = append(postings, findJobPostings(child)...)
+		}
+	case node.IsObject():
+		for _, t := range toStrings(node.Get("@type")) {
+			if t == "JobPosting" {
+				postings = append(postings, node)
+				break
+			}
+		}
+		postings = append(postings, findJobPostings(node.Get("@graph"))...)
+	}
+	return postings
+}
+
+// parseJobPosting converts a schema.org JobPosting into a job.
+func parseJobPosting(posting gjson.Result, pageURL string) *job.Job {
+	j := job.New()
+	j.Title = strings.TrimSpace(posting.Get("title").String())
+	j.Company = nameOf(posting.Get("hiringOrganization"))
+	j.Link = pageURL
+	if url := posting.Get("url").String(); strings.HasPrefix(url, "http") {
+		j.Link = url
+	}
+	j.MainCategory = firstString(posting.Get("occupationalCategory"))
+	if j.MainCategory == "" {
+		j.MainCategory = firstString(posting.Get("industry"))
+	}
+	j.EmploymentType = newEmploymentTypeFromSchema(firstString(posting.Get("employmentType")))
+	j.Location = parseJobLocation(posting.Get("jobLocation"))
+	j.NumberToHire = int(posting.Get("totalJobOpenings").Int())
+	j.Experience = parseExperienceRequirements(posting.Get("experienceRequirements"))
+	j.Salary = parseBaseSalary(posting.Get("baseSalary"))
+	j.Remote = job.NoRemote
+	if firstString(posting.Get("jobLocationType")) == "TELECOMMUTE" {
+		j.Remote = job.FullRemote
+	}
+	j.JobDescription = htmlparser.Parse(posting.Get("description").String())
+	j.Requirements = htmlparser.Parse(posting.Get("qualifications").String())
+	for _, skill := range toStrings(posting.Get("skills")) {
+		for _, tag := range strings.Split(skill, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				j.Tags = append(j.Tags, tag)
+			}
+		}
+	}
+	return j
+}
+
+// newEmploymentTypeFromSchema maps a schema.org employmentType value.
+func newEmploymentTypeFromSchema(s string) job.EmploymentType {
+	switch strings.ToUpper(s) {
+	case "FULL_TIME":
+		return job.FullTime
+	case "PART_TIME":
+		return job.PartTime
+	case "INTERN":
+		return job.Internship
+	case "CONTRACTOR":
+		return job.Contract
+	case "TEMPORARY":
+		return job.Temporary
+	case "VOLUNTEER":
+		return job.Volunteer
+	default:
+		return job.InvalidEmploymentType
+	}
+}
+
+// parseJobLocation formats the first jobLocation address like Cake does, from
+// the most to the least specific part.
+func parseJobLocation(jobLocation gjson.Result) string {
+	if jobLocation.IsArray() {
+		jobLocation = jobLocation.Get("0")
+	}
+	address := jobLocation.Get("address")
+	if !address.IsObject() {
+		return address.String()
+	}
+	parts := []string{
+		address.Get("addressLocality").String(),
+		address.Get("addressRegion").String(),
+		nameOf(address.Get("addressCountry")),
+	}
+	return strings.Join(util.Filter(parts, func(s string) bool { return s != "" }), ", ")
+}
+
+func parseExperienceRequirements(requirements gjson.Result) string {
+	if !requirements.IsObject() {
+		return requirements.String()
+	}
+	if months := requirements.Get("monthsOfExperience"); months.Exists() {
+		return fmt.Sprintf("%s months of experience required", formatNumber(months.Float()))
+	}
+	return requirements.Get("description").String()
+}
+
+// parseBaseSalary formats a MonetaryAmount like Cake does, e.g.
+// "40000 ~ 70000 TWD / month".
+func parseBaseSalary(baseSalary gjson.Result) string {
+	if !baseSalary.IsObject() {
+		return baseSalary.String()
+	}
+	currency := baseSalary.Get("currency").String()
+	value := baseSalary.Get("value")
+	var amount, unit string
+	if value.IsObject() {
+		unit = value.Get("unitText").String()
+		minValue, maxValue := value.Get("minValue"), value.Get("maxValue")
+		switch {
+		case minValue.Exists() && maxValue.Exists():
+			amount = formatNumber(minValue.Float()) + " ~ " + formatNumber(maxValue.Float())
+		case minValue.Exists():
+			amount = formatNumber(minValue.Float()) + "+"
+		case maxValue.Exists():
+			amount = "~ " + formatNumber(maxValue.Float())
+		default:
+			amount = formatNumber(value.Get("value").Float())
+		}
+	} else if value.Exists() {
+		amount = formatNumber(value.Float())
+	}
+	if amount == "" {
+		return ""
+	}
+	salary := strings.TrimSpace(amount + " " + currency)
+	if unit != "" {
+		salary += " / " + strings.ToLower(unit)
+	}
+	return salary
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// nameOf returns the name of a schema.org Thing, which may also be given as a
+// plain string.
+func nameOf(thing gjson.Result) string {
+	if thing.IsObject() {
+		return strings.TrimSpace(thing.Get("name").String())
+	}
+	return strings.TrimSpace(thing.String())
+}
+
+// firstString returns the value, or its first element if it is an array.
+func firstString(r gjson.Result) string {
+	if values := toStrings(r); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func toStrings(r gjson.Result) []string {
+	if !r.Exists() {
+		return nil
+	}
+	if r.IsArray() {
+		return util.Map(r.Array(), func(v gjson.Result) string { return v.String() })
+	}
+	return []string{r.String()}
+}
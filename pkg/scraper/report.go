@@ -0,0 +1,263 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/run"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const maxReportErrors = 100
+
+var (
+	ErrTooManyErrors = errors.New("scrape run aborted: too many errors")
+)
+
+// Report summarizes a scrape run. It is safe for concurrent use by the
+// collectors of a source.
+type Report struct {
+	mu             sync.Mutex
+	RunID          int64     `json:"run_id"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	Sources        []string  `json:"sources"`
+	Professions    []string  `json:"professions"`
+	PagesRequested int       `json:"pages_requested"`
+	PagesVisited   int       `json:"pages_visited"`
+	JobsSaved      int       `json:"jobs_saved"`
+	JobsNew        int       `json:"jobs_new"`
+	JobsUpdated    int       `json:"jobs_updated"`
+	JobsUnchanged  int       `json:"jobs_unchanged"`
+	JobsSkipped    int       `json:"jobs_skipped"`
+	JobsClosed     int       `json:"jobs_closed"`
+	// CompaniesSaved counts the company profiles scraped and saved.
+	CompaniesSaved int         `json:"companies_saved"`
+	ParseFailures  int         `json:"parse_failures"`
+	SaveFailures   int         `json:"save_failures"`
+	VisitFailures  int         `json:"visit_failures"`
+	HTTPErrors     map[int]int `json:"http_errors"`
+	// UnparsedSalaries counts the salaries the salary parser did not
+	// understand.
+	UnparsedSalaries map[string]int `json:"unparsed_salaries"`
+	// Errors holds the first error messages of the run.
+	Errors  []string `json:"errors"`
+	onError func(count int)
+	// skipped holds the links that were listed but not fetched again.
+	skipped []string
+	// closed holds the links whose detail page is gone.
+	closed []string
+	// listings holds the links of every listing that was crawled in full.
+	listings map[string][]string
+	// companies holds the slugs of the companies of the scraped jobs.
+	companies map[string]bool
+}
+
+func NewReport() *Report {
+	return &Report{
+		StartedAt:        time.Now(),
+		Sources:          []string{},
+		Professions:      []string{},
+		HTTPErrors:       map[int]int{},
+		UnparsedSalaries: map[string]int{},
+		Errors:           []string{},
+	}
+}
+
+// Requested records the list pages a source is about to request.
+func (r *Report) Requested(pages int, professions ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PagesRequested += pages
+	r.Professions = append(r.Professions, professions...)
+}
+
+func (r *Report) PageVisited() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PagesVisited++
+}
+
+func (r *Report) JobSaved(result jobrepo.SaveResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.JobsSaved++
+	switch result {
+	case jobrepo.Created:
+		r.JobsNew++
+	case jobrepo.Updated:
+		r.JobsUpdated++
+	case jobrepo.Unchanged:
+		r.JobsUnchanged++
+	}
+}
+
+// JobSkipped records a listed job whose detail page was not fetched again.
+func (r *Report) JobSkipped(link string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.JobsSkipped++
+	r.skipped = append(r.skipped, link)
+}
+
+// takeSkipped returns and clears the skipped links recorded so far.
+func (r *Report) takeSkipped() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	skipped := r.skipped
+	r.skipped = nil
+	return skipped
+}
+
+// companySeen records the company of a scraped job.
+func (r *Report) companySeen(slug string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.companies == nil {
+		r.companies = map[string]bool{}
+	}
+	r.companies[slug] = true
+}
+
+// takeCompanies returns and clears the company slugs recorded so far, in
+// order.
+func (r *Report) takeCompanies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	companies := slices.Sorted(maps.Keys(r.companies))
+	r.companies = nil
+	return companies
+}
+
+func (r *Report) CompanySaved() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.CompaniesSaved++
+}
+
+// SalaryUnparsed records a salary the salary parser did not understand.
+func (r *Report) SalaryUnparsed(salary string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.UnparsedSalaries[salary]++
+}
+
+// JobGone records a job whose detail page no longer exists.
+func (r *Report) JobGone(link string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = append(r.closed, link)
+}
+
+// Listed records every link of a listing, e.g. a profession, that was crawled
+// in full. Jobs it no longer lists are closed.
+func (r *Report) Listed(listing string, links []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listings == nil {
+		r.listings = map[string][]string{}
+	}
+	r.listings[listing] = append(r.listings[listing], links...)
+}
+
+// jobsClosed records jobs closed after they were found gone or unlisted.
+func (r *Report) jobsClosed(count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.JobsClosed += count
+}
+
+// takeClosed returns and clears the gone links recorded so far.
+func (r *Report) takeClosed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	closed := r.closed
+	r.closed = nil
+	return closed
+}
+
+// takeListings returns and clears the listings recorded so far.
+func (r *Report) takeListings() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listings := r.listings
+	r.listings = nil
+	return listings
+}
+
+// ParseFailed records a page that could not be parsed into a job.
+func (r *Report) ParseFailed(url string, err error) {
+	r.recordError(func() { r.ParseFailures++ }, fmt.Errorf("parse %s: %w", url, err))
+}
+
+// SaveFailed records a scraped job that could not be saved.
+func (r *Report) SaveFailed(url string, err error) {
+	r.recordError(func() { r.SaveFailures++ }, fmt.Errorf("save %s: %w", url, err))
+}
+
+// VisitFailed records a URL that could not be queued for a visit.
+func (r *Report) VisitFailed(url string, err error) {
+	r.recordError(func() { r.VisitFailures++ }, fmt.Errorf("visit %s: %w", url, err))
+}
+
+// HTTPError records a failed request. Network errors have status 0.
+func (r *Report) HTTPError(url string, status int, err error) {
+	r.recordError(func() { r.HTTPErrors[status]++ }, fmt.Errorf("fetch %s (%d): %w", url, status, err))
+}
+
+// ErrorCount returns the number of errors recorded so far.
+func (r *Report) ErrorCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errorCount()
+}
+
+func (r *Report) errorCount() int {
+	count := r.ParseFailures + r.SaveFailures + r.VisitFailures
+	for _, n := range r.HTTPErrors {
+		count += n
+	}
+	return count
+}
+
+func (r *Report) recordError(count func(), err error) {
+	r.mu.Lock()
+	count()
+	if len(r.Errors) < maxReportErrors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+	total := r.errorCount()
+	onError := r.onError
+	r.mu.Unlock()
+	if onError != nil {
+		onError(total)
+	}
+}
+
+func (r *Report) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FinishedAt = time.Now()
+}
+
+// toRun copies the report into the run record.
+func (r *Report) toRun(scrapeRun *run.Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scrapeRun.StartedAt = r.StartedAt
+	scrapeRun.FinishedAt = r.FinishedAt
+	scrapeRun.Sources = append([]string{}, r.Sources...)
+	scrapeRun.Professions = append([]string{}, r.Professions...)
+	scrapeRun.PagesRequested = r.PagesRequested
+	scrapeRun.PagesVisited = r.PagesVisited
+	scrapeRun.JobsNew = r.JobsNew
+	scrapeRun.JobsUpdated = r.JobsUpdated
+	scrapeRun.JobsUnchanged = r.JobsUnchanged
+	scrapeRun.JobsSkipped = r.JobsSkipped
+	scrapeRun.JobsClosed = r.JobsClosed
+	scrapeRun.Errors = r.errorCount()
+	scrapeRun.ErrorMessages = append([]string{}, r.Errors...)
+}
@@ -1,16 +1,19 @@
 package scraper
 
 import (
-	"cake-scraper/pkg/htmlparser"
+	"cake-scraper/pkg/company"
 	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/companyrepo"
 	"cake-scraper/pkg/repo/jobrepo"
 	"cake-scraper/pkg/repo/locationrepo"
+	"cake-scraper/pkg/repo/runrepo"
+	"cake-scraper/pkg/run"
 	"cake-scraper/pkg/util"
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
-	"regexp"
-	"strconv"
-	"strings"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gocolly/colly/v2"
@@ -19,28 +22,40 @@ import (
 )
 
 const (
-	BackendDeveloper  Profession = "it_back-end-engineer"
-	DataEngineer      Profession = "it_data-engineer"
-	FrontendDeveloper Profession = "it_front-end-engineer"
-	maxChanSize       int        = 100
-	rateLimit                    = 30
+	DefaultMaxErrors     = 100
+	maxChanSize      int = 100
+	rateLimit            = 30
 )
 
 var (
-	_                 Scraper = (*scraper)(nil)
-	jobListUrlRegex           = regexp.MustCompile(`^https://www.cake.me/jobs.*$`)
-	jobDetailUrlRegex         = regexp.MustCompile(`^https://www.cake.me/companies/(.*)/jobs/(.*)$`)
+	_ Scraper = (*scraper)(nil)
 )
 
-type Profession string
+// contextTransport cancels in-flight requests once ctx is done.
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
 
-func NewCollector() *colly.Collector {
+// NewCollector creates an asynchronous, rate-limited collector whose requests
+// are aborted once ctx is done.
+func NewCollector(ctx context.Context, options ...colly.CollectorOption) *colly.Collector {
 	c := colly.NewCollector(
-		colly.URLFilters(jobDetailUrlRegex, jobListUrlRegex),
-		colly.Async(true),
-		colly.AllowURLRevisit(),
+		append([]colly.CollectorOption{
+			colly.Async(true),
+			colly.AllowURLRevisit(),
+		}, options...)...,
 	)
+	c.WithTransport(&contextTransport{ctx: ctx, base: http.DefaultTransport})
 	c.OnRequest(func(r *colly.Request) {
+		if ctx.Err() != nil {
+			r.Abort()
+			return
+		}
 		r.Headers.Set("Cookie", "locale=en")
 	})
 	if err := c.Limit(&colly.LimitRule{
@@ -53,37 +68,44 @@ func NewCollector() *colly.Collector {
 	return c
 }
 
-func (p Profession) String() string {
-	return string(p)
-}
-
-func buildJobListUrl(profession Profession, page int) string {
-	return fmt.Sprintf("https://www.cake.me/jobs?location_list%%5B0%%5D=Taiwan&profession%%5B0%%5D=%s&order=latest&page=%d", profession, page)
-}
-
 type Scraper interface {
-	Query(conditions map[string]interface{}) []*job.Job
-	Update() error
+	Query(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error)
+	Update(ctx context.Context) (*Report, error)
 }
 
 type scraper struct {
-	Professions     []Profession
-	MaxPage         int
-	linkCollector   *colly.Collector
-	detailCollector *colly.Collector
-	jobRepo         jobrepo.JobRepo
-	locationRepo    locationrepo.LocationRepo
-	logger          *slog.Logger
+	Sources []Source
+	// MaxErrors is the number of errors a run tolerates before it is aborted.
+	// A negative value never aborts.
+	MaxErrors int
+	// Incremental makes sources that support it stop paginating once they
+	// reach known jobs, and skip jobs and companies fetched within Freshness.
+	Incremental bool
+	Freshness   time.Duration
+	// BatchSize is the number of scraped jobs saved per transaction.
+	BatchSize    int
+	jobRepo      jobrepo.JobRepo
+	companyRepo  companyrepo.CompanyRepo
+	locationRepo locationrepo.LocationRepo
+	runRepo      runrepo.RunRepo
+	logger       *slog.Logger
+}
+
+func NewScraper(Sources ...Source) *scraper {
+	return NewScraperWithRepos(jobrepo.NewJobRepo(), companyrepo.NewCompanyRepo(), locationrepo.NewLocationRepo(), runrepo.NewRunRepo(), Sources...)
 }
 
-func NewScraper(MaxPage int, Professions ...Profession) *scraper {
+// NewScraperWithRepos creates a scraper that writes to the given repositories
+// instead of the shared database, such as the in-memory repos.
+func NewScraperWithRepos(jobRepo jobrepo.JobRepo, companyRepo companyrepo.CompanyRepo, locationRepo locationrepo.LocationRepo, runRepo runrepo.RunRepo, Sources ...Source) *scraper {
 	s := &scraper{
-		MaxPage:         MaxPage,
-		Professions:     Professions,
-		linkCollector:   NewCollector(),
-		detailCollector: NewCollector(),
-		jobRepo:         jobrepo.NewJobRepo(),
-		locationRepo:    locationrepo.NewLocationRepo(),
+		Sources:      Sources,
+		MaxErrors:    DefaultMaxErrors,
+		BatchSize:    DefaultBatchSize,
+		jobRepo:      jobRepo,
+		companyRepo:  companyRepo,
+		locationRepo: locationRepo,
+		runRepo:      runRepo,
 	}
 	s.Init()
 	return s
@@ -91,135 +113,163 @@ func NewScraper(MaxPage int, Professions ...Profession) *scraper {
 
 func (s *scraper) Init() {
 	s.logger = slog.Default().WithGroup("scraper")
-	if err := s.locationRepo.Init(); err != nil {
+	if err := s.locationRepo.Init(context.Background()); err != nil {
 		util.PanicError(err)
 	}
-	s.linkCollector.OnHTML("div[class^='JobSearchHits_list__']", func(e *colly.HTMLElement) {
-		hrefs := e.ChildAttrs("a[class^='JobSearchItem_jobTitle__']", "href")
-		hrefs = util.Filter(hrefs, func(href string) bool {
-			return href != ""
-		})
-		links := util.Map(hrefs, func(href string) string {
-			return e.Request.AbsoluteURL(href)
-		})
-		for _, link := range links {
-			s.handleScrapedLink(link)
+}
+
+func (s *scraper) handleScrapedJob(writer *jobWriter, source Source, report *Report, j *job.Job) {
+	j.Source = source.Name()
+	if j.CompanySlug != "" {
+		report.companySeen(j.CompanySlug)
+	}
+	if j.ExperienceYears == nil && j.Experience != "" {
+		if years, err := job.ParseExperience(j.Experience); err == nil {
+			j.ExperienceYears = &years
 		}
-	})
-	s.detailCollector.OnHTML("body", func(e *colly.HTMLElement) {
-		j := job.New()
-		j.Company = e.ChildText("div[class^='JobDescriptionLeftColumn_companyInfo__'] > a > h2")
-		j.Title = e.ChildText("h1[class^='JobDescriptionLeftColumn_title__']")
-		j.Link = e.Request.URL.String()
-		j.Remote = job.NoRemote
-		// Job Category
-		e.ForEach("div[class^='Breadcrumbs_wrapper__']", func(_ int, div *colly.HTMLElement) {
-			categories := div.ChildTexts("a > span")
-			switch len(categories) {
-			case 1:
-				j.MainCategory = categories[0]
-			case 2:
-				j.MainCategory = categories[0]
-				j.SubCategory = categories[1]
-			}
-		})
-		// Job Info
-		e.ForEach("div[class^='JobDescriptionRightColumn_jobInfo__'] > div[class^='JobDescriptionRightColumn_row__']", func(_ int, row *colly.HTMLElement) {
-			icons := util.Filter(strings.Split(row.ChildAttr("i", "class"), " "), func(str string) bool {
-				return str != ""
-			})
-			anchors := row.ChildTexts("a")
-			spans := row.ChildTexts("span")
-			if len(icons) == 0 {
-				// EmploymentType, Seniority, Tags
-				for _, anchor := range anchors {
-					if employmentType := job.NewEmploymentType(anchor); employmentType != job.InvalidEmploymentType {
-						j.EmploymentType = employmentType
-					} else if seniority := job.NewSeniority(anchor); seniority != job.InvalidSeniority {
-						j.Seniority = seniority
-					} else {
-						j.Tags = append(j.Tags, anchor)
-					}
-				}
-			} else {
-				// Location, NumberToHire, Experience, Salary, Remote, Tags
-				for _, icon := range icons {
-					switch icon {
-					case "fa-map-marker-alt":
-						j.Location = anchors[0]
-					case "fa-user":
-						j.NumberToHire, _ = strconv.Atoi(spans[0])
-					case "fa-business-time":
-						j.Experience = spans[0]
-					case "fa-dollar-sign":
-						j.Salary = spans[0]
-					case "fa-house":
-						j.Remote = job.NewRemote(spans[0])
-					case "fa-ellipsis-h":
-						j.Tags = append(j.Tags, anchors[0])
-					}
-				}
-			}
-		})
-		// Job Content
-		e.ForEach("div[class^='ContentSection_contentSection__']", func(_ int, section *colly.HTMLElement) {
-			contentType := section.ChildText("h3[class^='ContentSection_title__']")
-			content, _ := section.DOM.Find("div[class^='RailsHtml_container__']").Html()
-			content = htmlparser.Parse(content)
-			if content == "" {
-				return
-			}
-			switch contentType {
-			case "Interview process":
-				j.InterviewProcess = content
-			case "Job Description":
-				j.JobDescription = content
-			case "Requirements":
-				j.Requirements = content
-			}
-		})
-		s.handleScrapedJob(j)
-	})
-	s.linkCollector.OnError(func(r *colly.Response, err error) {
-		if r.StatusCode == 404 {
-			return
+	}
+	if j.SalaryRange == nil && j.Salary != "" {
+		salaryRange, err := job.ParseSalary(j.Salary)
+		if err != nil {
+			report.SalaryUnparsed(j.Salary)
 		}
-		s.logger.Error("linkCollector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
-	})
-	s.detailCollector.OnError(func(r *colly.Response, err error) {
-		s.logger.Error("detailCollector on err:", "URL", r.Request.URL, "Code", r.StatusCode, "Error", err)
-	})
+		j.SalaryRange = salaryRange
+	}
+	writer.Write(j)
 }
 
-func (s *scraper) handleScrapedLink(link string) {
-	if err := s.detailCollector.Visit(link); err != nil {
-		util.PanicError(err)
+// closeJobs closes the jobs of source whose detail page is gone, or that a
+// fully crawled listing no longer lists.
+func (s *scraper) closeJobs(ctx context.Context, source Source, report *Report) {
+	closed, err := s.jobRepo.Close(ctx, report.takeClosed())
+	if err != nil {
+		s.logger.Error("failed to close gone jobs", "Error", err)
+		report.SaveFailed(source.Name(), err)
+	}
+	report.jobsClosed(closed)
+	for listing, links := range report.takeListings() {
+		closed, err := s.jobRepo.CloseUnlisted(ctx, source.Name(), listing, links)
+		if err != nil {
+			s.logger.Error("failed to close unlisted jobs", "Listing", listing, "Error", err)
+			report.SaveFailed(source.Name(), err)
+			continue
+		}
+		report.jobsClosed(closed)
 	}
 }
 
-func (s *scraper) handleScrapedJob(j *job.Job) {
-	if err := s.jobRepo.Save(j); err != nil {
-		util.PanicError(err)
+// scrapeCompanies scrapes the profiles of the companies of the jobs source
+// yielded, if it has profiles, and saves them.
+func (s *scraper) scrapeCompanies(ctx, recordCtx context.Context, source Source, report *Report) error {
+	slugs := report.takeCompanies()
+	companySource, ok := source.(CompanySource)
+	if !ok || len(slugs) == 0 || ctx.Err() != nil {
+		return nil
 	}
+	if s.Incremental {
+		lastFetched, err := s.companyRepo.LastFetched(ctx, slugs)
+		if err != nil {
+			s.logger.Error("failed to look up known companies", "Error", err)
+			lastFetched = map[string]time.Time{}
+		}
+		slugs = util.Filter(slugs, func(slug string) bool {
+			fetchedAt, ok := lastFetched[slug]
+			return !ok || time.Since(fetchedAt) >= s.Freshness
+		})
+	}
+	// Companies are few, so they are saved once scraped rather than by a
+	// writer.
+	var mu sync.Mutex
+	var companies []*company.Company
+	err := companySource.ScrapeCompanies(ctx, report, slugs, func(c *company.Company) {
+		mu.Lock()
+		defer mu.Unlock()
+		companies = append(companies, c)
+	})
+	for _, c := range companies {
+		if err := s.companyRepo.Save(recordCtx, c); err != nil {
+			s.logger.Error("failed to save company", "Slug", c.Slug, "Error", err)
+			report.SaveFailed(c.Slug, err)
+			continue
+		}
+		report.CompanySaved()
+	}
+	return err
 }
 
-func (s *scraper) Query(conditions map[string]interface{}) []*job.Job {
-	jobs, err := s.jobRepo.Find(conditions)
-	if err != nil {
-		util.PanicError(err)
-	}
-	return jobs
+func (s *scraper) Query(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error) {
+	return s.jobRepo.Find(ctx, conditions)
 }
 
-func (s *scraper) Update() error {
-	for _, profession := range s.Professions {
-		for page := 1; page <= s.MaxPage; page++ {
-			if err := s.linkCollector.Visit(buildJobListUrl(profession, page)); err != nil {
-				return err
-			}
+// Update scrapes every source and saves the jobs found. It stops early when
+// ctx is done or when the run records more than MaxErrors errors, and always
+// returns the report of what was done. The run is recorded in scrape_runs.
+func (s *scraper) Update(ctx context.Context) (report *Report, err error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	report = NewReport()
+	report.onError = func(count int) {
+		if s.MaxErrors >= 0 && count > s.MaxErrors {
+			cancel(ErrTooManyErrors)
+		}
+	}
+	scrapeRun := run.New(report.StartedAt)
+	if err := s.runRepo.Save(ctx, scrapeRun); err != nil {
+		return report, err
+	}
+	report.RunID = scrapeRun.ID
+	defer func() {
+		report.finish()
+		if len(report.UnparsedSalaries) > 0 {
+			s.logger.Warn("failed to parse salaries", "Counts", report.UnparsedSalaries)
+		}
+		report.toRun(scrapeRun)
+		switch {
+		case err == nil:
+			scrapeRun.Status = run.Succeeded
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			scrapeRun.Status = run.Canceled
+			scrapeRun.Error = err.Error()
+		default:
+			scrapeRun.Status = run.Failed
+			scrapeRun.Error = err.Error()
+		}
+		// The run is recorded even if ctx was canceled.
+		if saveErr := s.runRepo.Save(context.WithoutCancel(ctx), scrapeRun); saveErr != nil {
+			s.logger.Error("failed to save scrape run", "ID", scrapeRun.ID, "Error", saveErr)
+			err = errors.Join(err, saveErr)
+		}
+	}()
+	for _, source := range s.Sources {
+		s.logger.Info("scraping source", "source", source.Name())
+		report.Sources = append(report.Sources, source.Name())
+		// What was scraped is recorded even if the run was stopped early.
+		recordCtx := context.WithoutCancel(ctx)
+		writer := newJobWriter(recordCtx, s.jobRepo, report, s.BatchSize, s.logger)
+		yield := func(j *job.Job) {
+			s.handleScrapedJob(writer, source, report, j)
+		}
+		var err error
+		if incrementalSource, ok := source.(IncrementalSource); ok && s.Incremental {
+			err = incrementalSource.ScrapeIncremental(ctx, report, s.jobRepo, s.Freshness, yield)
+		} else {
+			err = source.Scrape(ctx, report, yield)
+		}
+		writer.Close()
+		if err == nil {
+			err = s.scrapeCompanies(ctx, recordCtx, source, report)
+		}
+		if seenErr := s.jobRepo.MarkSeen(recordCtx, report.takeSkipped(), report.RunID); seenErr != nil {
+			s.logger.Error("failed to mark skipped jobs as seen", "Error", seenErr)
+			report.SaveFailed(source.Name(), seenErr)
+		}
+		s.closeJobs(recordCtx, source, report)
+		if err != nil {
+			return report, err
+		}
+		if ctx.Err() != nil {
+			return report, context.Cause(ctx)
 		}
 	}
-	s.linkCollector.Wait()
-	s.detailCollector.Wait()
-	return nil
+	return report, nil
 }
@@ -0,0 +1,767 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/companyrepo"
+	"cake-scraper/pkg/repo/jobrepo"
+	"cake-scraper/pkg/repo/locationrepo"
+	"cake-scraper/pkg/run"
+	"cake-scraper/pkg/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// fakeJobRepo records saved jobs in memory.
+type fakeJobRepo struct {
+	mu        sync.Mutex
+	jobs      []*job.Job
+	fetchedAt map[string]time.Time
+	seen      []string
+	// batches are the sizes of the batches saved by SaveAll.
+	batches []int
+	// listings and closed track job lifecycle by link.
+	listings map[string][]string
+	closed   map[string]bool
+	// failing are the links of the jobs that fail to save.
+	failing map[string]bool
+}
+
+var errSaveFailed = errors.New("save failed")
+
+func (r *fakeJobRepo) Find(ctx context.Context, conditions map[string]interface{}) ([]*job.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*job.Job{}, r.jobs...), nil
+}
+
+func (r *fakeJobRepo) FindPaginated(ctx context.Context, conditions jobrepo.Conditions, page, perPage int64) (util.FalliblePaginator[*job.Job], error) {
+	jobs, _ := r.Find(ctx, nil)
+	return util.NewFalliblePaginator(func(offset, limit int64) ([]*job.Job, error) {
+		return jobs[offset : offset+limit], nil
+	}, page, perPage, int64(len(jobs))), nil
+}
+
+func (r *fakeJobRepo) FindByID(ctx context.Context, id int64) (*job.Job, error) {
+	return nil, nil
+}
+
+func (r *fakeJobRepo) FindRevisions(ctx context.Context, jobID int64) ([]*job.Revision, error) {
+	return nil, nil
+}
+
+func (r *fakeJobRepo) CountTags(ctx context.Context, conditions jobrepo.Conditions, limit int64) ([]*job.TagCount, error) {
+	return nil, nil
+}
+
+func (r *fakeJobRepo) Save(ctx context.Context, j *job.Job, runID int64) (jobrepo.SaveResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failing[j.Link] {
+		return jobrepo.Created, errSaveFailed
+	}
+	if r.fetchedAt == nil {
+		r.fetchedAt = map[string]time.Time{}
+	}
+	r.fetchedAt[j.Link] = time.Now()
+	delete(r.closed, j.Link)
+	for i, stored := range r.jobs {
+		if stored.Link != j.Link {
+			continue
+		}
+		if reflect.DeepEqual(stored, j) {
+			return jobrepo.Unchanged, nil
+		}
+		r.jobs[i] = j
+		return jobrepo.Updated, nil
+	}
+	r.jobs = append(r.jobs, j)
+	return jobrepo.Created, nil
+}
+
+func (r *fakeJobRepo) SaveAll(ctx context.Context, jobs []*job.Job, runID int64) ([]jobrepo.SaveResult, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, len(jobs))
+	// Like a transaction, a failing job saves none of the batch.
+	for _, j := range jobs {
+		if r.failing[j.Link] {
+			r.mu.Unlock()
+			return nil, errSaveFailed
+		}
+	}
+	r.mu.Unlock()
+	results := []jobrepo.SaveResult{}
+	for _, j := range jobs {
+		result, err := r.Save(ctx, j, runID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// blockingJobRepo saves nothing until a batch is released, telling when each
+// batch starts being saved.
+type blockingJobRepo struct {
+	fakeJobRepo
+	started chan int
+	release chan struct{}
+}
+
+func (r *blockingJobRepo) SaveAll(ctx context.Context, jobs []*job.Job, runID int64) ([]jobrepo.SaveResult, error) {
+	r.started <- len(jobs)
+	<-r.release
+	return r.fakeJobRepo.SaveAll(ctx, jobs, runID)
+}
+
+func (r *fakeJobRepo) LastFetched(ctx context.Context, links []string) (map[string]time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lastFetched := map[string]time.Time{}
+	for _, link := range links {
+		if fetchedAt, ok := r.fetchedAt[link]; ok {
+			lastFetched[link] = fetchedAt
+		}
+	}
+	return lastFetched, nil
+}
+
+func (r *fakeJobRepo) MarkSeen(ctx context.Context, links []string, runID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, links...)
+	for _, link := range links {
+		delete(r.closed, link)
+	}
+	return nil
+}
+
+// close closes the known, open jobs with the given links. r.mu must be held.
+func (r *fakeJobRepo) close(links []string) int {
+	if r.closed == nil {
+		r.closed = map[string]bool{}
+	}
+	closed := 0
+	for _, link := range links {
+		if _, ok := r.fetchedAt[link]; ok && !r.closed[link] {
+			r.closed[link] = true
+			closed++
+		}
+	}
+	return closed
+}
+
+func (r *fakeJobRepo) Close(ctx context.Context, links []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close(links), nil
+}
+
+func (r *fakeJobRepo) CloseUnlisted(ctx context.Context, source, listing string, links []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listings == nil {
+		r.listings = map[string][]string{}
+	}
+	unlisted := util.Filter(r.listings[listing], func(link string) bool {
+		return !slices.Contains(links, link)
+	})
+	r.listings[listing] = links
+	return r.close(unlisted), nil
+}
+
+func (r *fakeJobRepo) Delete(ctx context.Context, conditions map[string]interface{}) error {
+	return nil
+}
+
+// fakeRunRepo keeps scrape runs in memory.
+type fakeRunRepo struct {
+	runs []*run.Run
+}
+
+func (r *fakeRunRepo) FindLatest(ctx context.Context, limit int64) ([]*run.Run, error) {
+	return r.runs, nil
+}
+
+func (r *fakeRunRepo) FindByID(ctx context.Context, id int64) (*run.Run, error) {
+	for _, scrapeRun := range r.runs {
+		if scrapeRun.ID == id {
+			return scrapeRun, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRunRepo) FindJobLinks(ctx context.Context, id int64) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeRunRepo) Save(ctx context.Context, scrapeRun *run.Run) error {
+	if scrapeRun.ID == 0 {
+		r.runs = append(r.runs, scrapeRun)
+		scrapeRun.ID = int64(len(r.runs))
+	}
+	return nil
+}
+
+// newFixtureServer serves the recorded Cake pages under testdata. List pages
+// live at testdata/jobs/{profession}/{page}.html and detail pages mirror their
+// URL path, e.g. testdata/companies/{company}/jobs/{job}.html.
+func newFixtureServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(serveFixture))
+}
+
+func serveFixture(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if path == "/jobs" {
+		query := r.URL.Query()
+		path = filepath.Join("/jobs", query.Get("profession[0]"), query.Get("page"))
+	}
+	data, err := os.ReadFile(filepath.Join("testdata", filepath.FromSlash(path)+".html"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+type ScraperTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (s *ScraperTestSuite) SetupTest() {
+	s.server = newFixtureServer()
+}
+
+func (s *ScraperTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ScraperTestSuite) assertGolden(name string, jobs []*job.Job) {
+	for _, j := range jobs {
+		j.Link = strings.TrimPrefix(j.Link, s.server.URL)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Link < jobs[k].Link
+	})
+	got, err := json.MarshalIndent(jobs, "", "    ")
+	if !s.NoError(err) {
+		return
+	}
+	goldenPath := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		s.NoError(os.WriteFile(goldenPath, append(got, '\n'), 0644))
+		return
+	}
+	want, err := os.ReadFile(goldenPath)
+	if !s.NoError(err) {
+		return
+	}
+	s.JSONEq(string(want), string(got))
+}
+
+func (s *ScraperTestSuite) TestUpdate() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	// Formosa Data has no company page.
+	s.Equal(7, report.PagesVisited)
+	s.Equal(3, report.JobsSaved)
+	s.Equal(2, report.CompaniesSaved)
+	s.Zero(report.ErrorCount())
+	s.Empty(report.UnparsedSalaries)
+	s.assertGolden("backend", repo.jobs)
+}
+
+func (s *ScraperTestSuite) TestUpdate_MemoryRepo() {
+	// Given
+	repo := jobrepo.NewMemoryJobRepo()
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(3, report.JobsSaved)
+	jobs, err := repo.FindPaginated(context.Background(), jobrepo.NewConditions().Active(), 1, 10)
+	s.Require().NoError(err)
+	s.Equal(int64(3), jobs.Total())
+}
+
+func (s *ScraperTestSuite) TestUpdate_Companies() {
+	// Given
+	companyRepo := companyrepo.NewMemoryCompanyRepo()
+	sc := NewScraperWithRepos(&fakeJobRepo{}, companyRepo, locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+
+	// When
+	_, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	acme, err := companyRepo.FindBySlug(context.Background(), "acme-labs")
+	s.Require().NoError(err)
+	s.Require().NotNil(acme)
+	s.Equal("Acme Labs", acme.Name)
+	s.Equal("Software", acme.Industry)
+	s.Equal("51-200", acme.Size)
+	s.Equal(2015, acme.FoundedYear)
+	s.Equal("Xinyi District, Taipei City, Taiwan", acme.Location)
+	s.Equal("https://acme-labs.example.com", acme.Website)
+	s.Equal([]string{"Remote work two days a week", "Stock options", "Annual learning budget"}, acme.Benefits)
+	pixel, err := companyRepo.FindBySlug(context.Background(), "pixel-cloud")
+	s.Require().NoError(err)
+	s.Require().NotNil(pixel)
+	s.Equal("Cloud Computing", pixel.Industry)
+	s.Zero(pixel.FoundedYear)
+	s.Empty(pixel.Benefits)
+	formosa, err := companyRepo.FindBySlug(context.Background(), "formosa-data")
+	s.Require().NoError(err)
+	s.Nil(formosa)
+}
+
+func (s *ScraperTestSuite) TestUpdate_Batches() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+	sc.BatchSize = 2
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(3, report.JobsSaved)
+	s.Len(repo.jobs, 3)
+	for _, size := range repo.batches {
+		s.LessOrEqual(size, 2)
+	}
+}
+
+func (s *ScraperTestSuite) TestJobWriter_BackPressure() {
+	// Given
+	repo := &blockingJobRepo{started: make(chan int), release: make(chan struct{})}
+	report := NewReport()
+	writer := newJobWriter(context.Background(), repo, report, 2, slog.Default())
+	writeJob := func(n int) {
+		writer.Write(&job.Job{Link: fmt.Sprint("https://www.cake.me/jobs/", n)})
+	}
+	writeJob(1)
+	s.Equal(1, <-repo.started)
+	writeJob(2)
+	writeJob(3)
+
+	// When
+	written := make(chan struct{})
+	go func() {
+		writeJob(4)
+		close(written)
+	}()
+
+	// Then
+	select {
+	case <-written:
+		s.Fail("Write should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(repo.release)
+	<-written
+	s.Equal(2, <-repo.started)
+	s.Equal(1, <-repo.started)
+	writer.Close()
+	s.Equal(4, report.JobsSaved)
+	s.Equal([]int{1, 2, 1}, repo.batches)
+}
+
+func (s *ScraperTestSuite) TestJobWriter_SavesFailedBatchOneByOne() {
+	// Given
+	failing := "https://www.cake.me/jobs/2"
+	repo := &blockingJobRepo{
+		fakeJobRepo: fakeJobRepo{failing: map[string]bool{failing: true}},
+		started:     make(chan int, 2),
+		release:     make(chan struct{}),
+	}
+	report := NewReport()
+	writer := newJobWriter(context.Background(), repo, report, 3, slog.Default())
+	writer.Write(&job.Job{Link: "https://www.cake.me/jobs/0"})
+	s.Equal(1, <-repo.started)
+	for n := 1; n <= 3; n++ {
+		writer.Write(&job.Job{Link: fmt.Sprint("https://www.cake.me/jobs/", n)})
+	}
+
+	// When
+	close(repo.release)
+	writer.Close()
+
+	// Then
+	s.Equal([]int{1, 3}, repo.batches)
+	s.Equal(3, report.JobsSaved)
+	s.Equal(1, report.SaveFailures)
+	s.Equal([]string{
+		"https://www.cake.me/jobs/0",
+		"https://www.cake.me/jobs/1",
+		"https://www.cake.me/jobs/3",
+	}, util.Map(repo.jobs, func(j *job.Job) string { return j.Link }))
+}
+
+func (s *ScraperTestSuite) TestUpdate_UnknownProfession() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 2, DataEngineer))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	s.NoError(err)
+	s.Empty(repo.jobs)
+	s.Zero(report.PagesVisited)
+}
+
+func (s *ScraperTestSuite) TestUpdate_JSONLD() {
+	// Given
+	repo := &fakeJobRepo{}
+	source := NewJSONLDSource("jsonld", "a.job-link", s.server.URL+"/jsonld/jobs")
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, source)
+
+	// When
+	_, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.assertGolden("jsonld", repo.jobs)
+}
+
+func (s *ScraperTestSuite) TestUpdate_Errors() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 1, FrontendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(1, report.JobsSaved)
+	s.Empty(report.HTTPErrors)
+	s.Equal(1, report.VisitFailures)
+	s.Len(report.Errors, 1)
+}
+
+func (s *ScraperTestSuite) TestUpdate_ClosesGoneJobs() {
+	// Given
+	gone := s.server.URL + "/companies/pixel-cloud/jobs/frontend-engineer"
+	repo := &fakeJobRepo{fetchedAt: map[string]time.Time{gone: time.Now()}}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 1, FrontendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(1, report.JobsClosed)
+	s.Equal(map[string]bool{gone: true}, repo.closed)
+}
+
+func (s *ScraperTestSuite) TestUpdate_ClosesUnlistedJobs() {
+	// Given
+	unlisted := s.server.URL + "/companies/acme-labs/jobs/retired-job"
+	repo := &fakeJobRepo{
+		fetchedAt: map[string]time.Time{unlisted: time.Now()},
+		listings:  map[string][]string{BackendDeveloper.String(): {unlisted}},
+	}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(1, report.JobsClosed)
+	s.Equal(map[string]bool{unlisted: true}, repo.closed)
+	s.Len(repo.listings[BackendDeveloper.String()], 3)
+}
+
+func (s *ScraperTestSuite) TestUpdate_MaxPageKeepsJobsOpen() {
+	// Given every page up to MaxPage lists jobs
+	unlisted := s.server.URL + "/companies/acme-labs/jobs/retired-job"
+	repo := &fakeJobRepo{
+		fetchedAt: map[string]time.Time{unlisted: time.Now()},
+		listings:  map[string][]string{BackendDeveloper.String(): {unlisted}},
+	}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 2, BackendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Zero(report.JobsClosed)
+	s.Empty(repo.closed)
+	s.Equal([]string{unlisted}, repo.listings[BackendDeveloper.String()])
+}
+
+func (s *ScraperTestSuite) TestUpdate_PartialCrawlKeepsJobsOpen() {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jobs" && r.URL.Query().Get("page") == "2" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		serveFixture(w, r)
+	}))
+	defer server.Close()
+	unlisted := server.URL + "/companies/acme-labs/jobs/retired-job"
+	repo := &fakeJobRepo{
+		fetchedAt: map[string]time.Time{unlisted: time.Now()},
+		listings:  map[string][]string{BackendDeveloper.String(): {unlisted}},
+	}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(server.URL, 3, BackendDeveloper))
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Zero(report.JobsClosed)
+	s.Empty(repo.closed)
+	s.Equal([]string{unlisted}, repo.listings[BackendDeveloper.String()])
+}
+
+func (s *ScraperTestSuite) TestUpdate_TooManyErrors() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 1, FrontendDeveloper), newCakeSource(s.server.URL, 1, BackendDeveloper))
+	sc.MaxErrors = 0
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	s.ErrorIs(err, ErrTooManyErrors)
+	s.Positive(report.ErrorCount())
+	s.False(report.FinishedAt.IsZero())
+}
+
+func (s *ScraperTestSuite) TestUpdate_RecordsRuns() {
+	// Given
+	repo := &fakeJobRepo{}
+	runRepo := &fakeRunRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), runRepo, newCakeSource(s.server.URL, 2, BackendDeveloper))
+
+	// When
+	first, err := sc.Update(context.Background())
+	if !s.NoError(err) {
+		return
+	}
+	second, err := sc.Update(context.Background())
+	if !s.NoError(err) {
+		return
+	}
+
+	// Then
+	if !s.Len(runRepo.runs, 2) {
+		return
+	}
+	s.Equal(int64(1), first.RunID)
+	s.Equal(int64(2), second.RunID)
+	s.Equal(run.Succeeded, runRepo.runs[0].Status)
+	s.Equal([]string{CakeSourceName}, runRepo.runs[0].Sources)
+	s.Equal([]string{BackendDeveloper.String()}, runRepo.runs[0].Professions)
+	s.Equal(2, runRepo.runs[0].PagesRequested)
+	s.Equal(3, runRepo.runs[0].JobsNew)
+	s.Equal(0, runRepo.runs[1].JobsNew)
+	s.Equal(3, runRepo.runs[1].JobsUnchanged)
+	s.False(runRepo.runs[1].FinishedAt.IsZero())
+}
+
+func (s *ScraperTestSuite) TestUpdate_RecordsFailedRun() {
+	// Given
+	runRepo := &fakeRunRepo{}
+	sc := NewScraperWithRepos(&fakeJobRepo{}, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), runRepo, newCakeSource(s.server.URL, 1, FrontendDeveloper))
+	sc.MaxErrors = 0
+
+	// When
+	_, err := sc.Update(context.Background())
+
+	// Then
+	s.ErrorIs(err, ErrTooManyErrors)
+	if !s.Len(runRepo.runs, 1) {
+		return
+	}
+	s.Equal(run.Failed, runRepo.runs[0].Status)
+	s.Equal(ErrTooManyErrors.Error(), runRepo.runs[0].Error)
+	s.Positive(runRepo.runs[0].Errors)
+}
+
+func (s *ScraperTestSuite) TestUpdate_Incremental() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+	if _, err := sc.Update(context.Background()); !s.NoError(err) {
+		return
+	}
+	sc.Incremental = true
+	sc.Freshness = time.Hour
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(1, report.PagesVisited)
+	s.Equal(2, report.JobsSkipped)
+	s.Zero(report.JobsSaved)
+	s.ElementsMatch([]string{
+		s.server.URL + "/companies/acme-labs/jobs/senior-backend-engineer-go",
+		s.server.URL + "/companies/pixel-cloud/jobs/platform-engineer",
+	}, repo.seen)
+}
+
+func (s *ScraperTestSuite) TestUpdate_IncrementalRefetchesStaleJobs() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+	if _, err := sc.Update(context.Background()); !s.NoError(err) {
+		return
+	}
+	sc.Incremental = true
+	sc.Freshness = 0
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(5, report.PagesVisited)
+	s.Zero(report.JobsSkipped)
+	s.Equal(2, report.JobsUnchanged)
+}
+
+func (s *ScraperTestSuite) TestUpdate_IncrementalWalksNewPages() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+	sc.Incremental = true
+	sc.Freshness = time.Hour
+
+	// When
+	report, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(7, report.PagesVisited)
+	s.Equal(3, report.JobsNew)
+	s.assertGolden("backend", repo.jobs)
+}
+
+func (s *ScraperTestSuite) TestUpdate_IncrementalStopsAtEmptyPage() {
+	// Given
+	var mu sync.Mutex
+	listPages := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jobs" {
+			mu.Lock()
+			listPages = append(listPages, r.URL.Query().Get("page"))
+			mu.Unlock()
+		}
+		serveFixture(w, r)
+	}))
+	defer server.Close()
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(server.URL, 5, FrontendDeveloper))
+	sc.Incremental = true
+	sc.Freshness = time.Hour
+
+	// When
+	_, err := sc.Update(context.Background())
+
+	// Then
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal([]string{"1", "2"}, listPages)
+	s.NotEmpty(repo.listings[FrontendDeveloper.String()])
+}
+
+func (s *ScraperTestSuite) TestUpdate_Canceled() {
+	// Given
+	repo := &fakeJobRepo{}
+	sc := NewScraperWithRepos(repo, companyrepo.NewMemoryCompanyRepo(), locationrepo.NewMemoryLocationRepo(), &fakeRunRepo{}, newCakeSource(s.server.URL, 3, BackendDeveloper))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	report, err := sc.Update(ctx)
+
+	// Then
+	s.ErrorIs(err, context.Canceled)
+	s.Zero(report.PagesVisited)
+	s.Empty(repo.jobs)
+}
+
+func (s *ScraperTestSuite) TestUrlFilters() {
+	s.True(jobListUrlRegex(s.server.URL).MatchString(buildJobListUrl(s.server.URL, BackendDeveloper, 1)))
+	s.True(jobDetailUrlRegex(s.server.URL).MatchString(s.server.URL + "/companies/acme-labs/jobs/platform-engineer"))
+	s.False(jobListUrlRegex(s.server.URL).MatchString(buildJobListUrl(DefaultBaseURL, BackendDeveloper, 1)))
+	s.False(jobDetailUrlRegex(DefaultBaseURL).MatchString(s.server.URL + "/companies/acme-labs/jobs/platform-engineer"))
+	s.True(companyUrlRegex(s.server.URL).MatchString(buildCompanyUrl(s.server.URL, "acme-labs")))
+	s.False(companyUrlRegex(s.server.URL).MatchString(s.server.URL + "/companies/acme-labs/jobs/platform-engineer"))
+}
+
+func TestScraperTestSuite(t *testing.T) {
+	suite.Run(t, new(ScraperTestSuite))
+}
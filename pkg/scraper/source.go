@@ -0,0 +1,42 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/company"
+	"cake-scraper/pkg/job"
+	"context"
+	"time"
+)
+
+// Source is a job board that can be scraped into job.Job values.
+type Source interface {
+	// Name returns the identifier stored with every job scraped from this source.
+	Name() string
+	// Scrape visits the job board and calls yield for every scraped job,
+	// recording visited pages and per-URL failures in report. It returns once
+	// every page has been visited or ctx is done.
+	Scrape(ctx context.Context, report *Report, yield func(j *job.Job)) error
+}
+
+// LinkIndex tells when known job links were last fetched.
+type LinkIndex interface {
+	LastFetched(ctx context.Context, links []string) (map[string]time.Time, error)
+}
+
+// IncrementalSource is a Source that can skip jobs it has already seen.
+type IncrementalSource interface {
+	Source
+	// ScrapeIncremental is like Scrape, but stops paginating once a list page
+	// only holds links known to index, and skips detail pages of links fetched
+	// within freshness.
+	ScrapeIncremental(ctx context.Context, report *Report, index LinkIndex, freshness time.Duration, yield func(j *job.Job)) error
+}
+
+// CompanySource is a Source whose jobs link to company profiles, by
+// job.Job.CompanySlug.
+type CompanySource interface {
+	Source
+	// ScrapeCompanies visits the profiles of the companies with the given
+	// slugs and calls yield for every scraped company, recording visited pages
+	// and failures in report. Companies without a profile are left out.
+	ScrapeCompanies(ctx context.Context, report *Report, slugs []string, yield func(c *company.Company)) error
+}
@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"cake-scraper/pkg/job"
+	"cake-scraper/pkg/repo/jobrepo"
+	"context"
+	"log/slog"
+)
+
+// DefaultBatchSize is the number of scraped jobs saved per transaction.
+const DefaultBatchSize = 50
+
+// jobWriter saves scraped jobs from a single goroutine, in batches of up to
+// batchSize jobs each saved in one transaction, so that parallel collectors
+// do not contend for the database. Once batchSize jobs are queued, Write
+// blocks until the pending batch is saved, slowing the collectors down.
+type jobWriter struct {
+	ctx       context.Context
+	repo      jobrepo.JobRepo
+	report    *Report
+	batchSize int
+	logger    *slog.Logger
+	jobs      chan *job.Job
+	done      chan struct{}
+}
+
+// newJobWriter starts a writer saving jobs to repo and recording the results
+// in report.
+func newJobWriter(ctx context.Context, repo jobrepo.JobRepo, report *Report, batchSize int, logger *slog.Logger) *jobWriter {
+	batchSize = max(batchSize, 1)
+	w := &jobWriter{
+		ctx:       ctx,
+		repo:      repo,
+		report:    report,
+		batchSize: batchSize,
+		logger:    logger,
+		jobs:      make(chan *job.Job, batchSize),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues j to be saved, blocking while the queue is full.
+func (w *jobWriter) Write(j *job.Job) {
+	w.jobs <- j
+}
+
+// Close saves the queued jobs and returns once they are saved. Write must not
+// be called afterwards.
+func (w *jobWriter) Close() {
+	close(w.jobs)
+	<-w.done
+}
+
+func (w *jobWriter) run() {
+	defer close(w.done)
+	batch := make([]*job.Job, 0, w.batchSize)
+	for j := range w.jobs {
+		batch = append(batch, j)
+		// Take the jobs queued meanwhile rather than waiting for a full batch.
+	fill:
+		for len(batch) < w.batchSize {
+			select {
+			case j, ok := <-w.jobs:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, j)
+			default:
+				break fill
+			}
+		}
+		w.save(batch)
+		batch = batch[:0]
+	}
+}
+
+// save saves batch in one transaction. As a single job failing rolls back
+// the whole batch, a failed batch is saved again one job at a time, so that
+// only the failing jobs are lost.
+func (w *jobWriter) save(batch []*job.Job) {
+	results, err := w.repo.SaveAll(w.ctx, batch, w.report.RunID)
+	if err == nil {
+		for _, result := range results {
+			w.report.JobSaved(result)
+		}
+		return
+	}
+	if len(batch) == 1 || w.ctx.Err() != nil {
+		w.logger.Error("failed to save jobs", "Jobs", len(batch), "Error", err)
+		for _, j := range batch {
+			w.report.SaveFailed(j.Link, err)
+		}
+		return
+	}
+	w.logger.Warn("failed to save jobs, saving them one by one", "Jobs", len(batch), "Error", err)
+	for _, j := range batch {
+		result, err := w.repo.Save(w.ctx, j, w.report.RunID)
+		if err != nil {
+			w.logger.Error("failed to save job", "URL", j.Link, "Error", err)
+			w.report.SaveFailed(j.Link, err)
+			continue
+		}
+		w.report.JobSaved(result)
+	}
+}
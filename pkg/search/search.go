@@ -0,0 +1,32 @@
+package search
+
+import (
+	"cake-scraper/pkg/repo/jobrepo"
+	"time"
+)
+
+// Search is a named set of job conditions saved to be run again.
+type Search struct {
+	ID         int64
+	Name       string
+	Conditions jobrepo.Conditions
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// LastViewedAt is when the jobs of the search were last checked, or zero
+	// if they never were.
+	LastViewedAt time.Time
+}
+
+func New(name string, conditions jobrepo.Conditions) *Search {
+	return &Search{
+		Name:       name,
+		Conditions: conditions,
+	}
+}
+
+// NewJobs returns the conditions of the jobs first seen since the last check
+// until before until, within the first seen range of the search. Every job
+// matching is new if the search was never checked. A zero until is open.
+func (s *Search) NewJobs(until time.Time) jobrepo.Conditions {
+	return s.Conditions.FirstSeenWithin(s.LastViewedAt, until)
+}
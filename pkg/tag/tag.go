@@ -0,0 +1,54 @@
+package tag
+
+import (
+	"cake-scraper/pkg/util"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var dictionaryPath = filepath.Join(util.ProjectRoot, "json/tags.json")
+
+var (
+	// dictionary maps each normalized alias, and canonical tag, to the
+	// canonical tag.
+	dictionary     map[string]string
+	dictionaryOnce sync.Once
+)
+
+// LoadDictionary loads the tag dictionary from json file, which maps each
+// canonical tag to its aliases. It is loaded once and shared by every
+// goroutine.
+func LoadDictionary() map[string]string {
+	dictionaryOnce.Do(func() {
+		data, err := os.ReadFile(dictionaryPath)
+		util.PanicError(err)
+		var aliases map[string][]string
+		util.PanicError(json.Unmarshal(data, &aliases))
+		dictionary = make(map[string]string)
+		for canonical, names := range aliases {
+			dictionary[normalize(canonical)] = canonical
+			for _, name := range names {
+				dictionary[normalize(name)] = canonical
+			}
+		}
+	})
+	return dictionary
+}
+
+// Canonical returns the canonical spelling of tag, comparing tags regardless
+// of case and spacing. Tags missing from the dictionary are their own
+// canonical tag.
+func Canonical(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if canonical, ok := LoadDictionary()[normalize(tag)]; ok {
+		return canonical
+	}
+	return tag
+}
+
+func normalize(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
@@ -0,0 +1,36 @@
+package tag_test
+
+import (
+	"cake-scraper/pkg/tag"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TagTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TagTestSuite) TestCanonical() {
+	testcases := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "Go", want: "Go"},
+		{tag: "golang", want: "Go"},
+		{tag: "GO", want: "Go"},
+		{tag: " Golang  1.x ", want: "Go"},
+		{tag: "k8s", want: "Kubernetes"},
+		{tag: "kubernetes", want: "Kubernetes"},
+		// Missing from the dictionary
+		{tag: "Rust", want: "Rust"},
+		{tag: " Rust ", want: "Rust"},
+	}
+	for _, tc := range testcases {
+		suite.Equal(tc.want, tag.Canonical(tc.tag), tc.tag)
+	}
+}
+
+func TestTagTestSuite(t *testing.T) {
+	suite.Run(t, new(TagTestSuite))
+}
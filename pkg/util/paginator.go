@@ -1,20 +1,13 @@
 package util
 
-type Paginator[T any] interface {
-	// Slice returns the slice function
-	Slice(offset, limit int64) []T
+// Pages describes the pages of a paginated list.
+type Pages interface {
 	// CurrentPage returns the current page number
 	CurrentPage() int64
-	// Items returns the items in the current page
-	Items() []T
 	// HasPrev returns true if there is a previous page
 	HasPrev() bool
-	// Prev returns the previous page
-	Prev() Paginator[T]
 	// HasNext returns true if there is a next page
 	HasNext() bool
-	// Next returns the next page
-	Next() Paginator[T]
 	// Offset returns the offset of the current page
 	Offset() int64
 	// Count returns the number of items in the current page
@@ -27,64 +20,132 @@ type Paginator[T any] interface {
 	TotalPage() int64
 }
 
-type paginator[T any] struct {
-	slice       func(offset, limit int64) []T
+type Paginator[T any] interface {
+	Pages
+	// Slice returns the slice function
+	Slice(offset, limit int64) []T
+	// Items returns the items in the current page
+	Items() []T
+	// Prev returns the previous page
+	Prev() Paginator[T]
+	// Next returns the next page
+	Next() Paginator[T]
+}
+
+// FalliblePaginator is a Paginator whose pages are fetched by a function that
+// can fail, such as a database query.
+type FalliblePaginator[T any] interface {
+	Pages
+	// Slice returns the slice function
+	Slice(offset, limit int64) ([]T, error)
+	// Items returns the items in the current page
+	Items() ([]T, error)
+	// Prev returns the previous page
+	Prev() FalliblePaginator[T]
+	// Next returns the next page
+	Next() FalliblePaginator[T]
+}
+
+type pages struct {
 	currentPage int64
 	perPage     int64
 	total       int64
 }
 
+type paginator[T any] struct {
+	pages
+	slice func(offset, limit int64) []T
+}
+
+type falliblePaginator[T any] struct {
+	pages
+	slice func(offset, limit int64) ([]T, error)
+}
+
 func NewPaginator[T any](slice func(offset, limit int64) []T, currentPage, perPage, total int64) Paginator[T] {
-	return &paginator[T]{slice: slice, currentPage: currentPage, perPage: perPage, total: total}
+	return &paginator[T]{pages: pages{currentPage: currentPage, perPage: perPage, total: total}, slice: slice}
 }
 
-func (p *paginator[T]) Slice(offset, limit int64) []T {
-	offset = min(max(offset, 0), p.total)
-	limit = max(min(limit, p.total-offset), 0)
-	return p.slice(offset, limit)
+func NewFalliblePaginator[T any](slice func(offset, limit int64) ([]T, error), currentPage, perPage, total int64) FalliblePaginator[T] {
+	return &falliblePaginator[T]{pages: pages{currentPage: currentPage, perPage: perPage, total: total}, slice: slice}
 }
 
-func (p *paginator[T]) CurrentPage() int64 {
-	return p.currentPage
+func (p *paginator[T]) Slice(offset, limit int64) []T {
+	offset, limit = p.bound(offset, limit)
+	return p.slice(offset, limit)
 }
 
 func (p *paginator[T]) Items() []T {
 	return p.slice(p.Offset(), p.Count())
 }
 
-func (p *paginator[T]) HasPrev() bool {
-	return p.currentPage > 1
+func (p *paginator[T]) Prev() Paginator[T] {
+	return &paginator[T]{pages: p.page(p.currentPage - 1), slice: p.slice}
 }
 
-func (p *paginator[T]) Prev() Paginator[T] {
-	return &paginator[T]{slice: p.slice, currentPage: p.currentPage - 1, total: p.total, perPage: p.perPage}
+func (p *paginator[T]) Next() Paginator[T] {
+	return &paginator[T]{pages: p.page(p.currentPage + 1), slice: p.slice}
 }
 
-func (p *paginator[T]) HasNext() bool {
-	return p.currentPage < p.TotalPage()
+func (p *falliblePaginator[T]) Slice(offset, limit int64) ([]T, error) {
+	offset, limit = p.bound(offset, limit)
+	return p.slice(offset, limit)
 }
 
-func (p *paginator[T]) Next() Paginator[T] {
-	return &paginator[T]{slice: p.slice, currentPage: p.currentPage + 1, total: p.total, perPage: p.perPage}
+// Items returns the items in the current page, or none past the last page.
+func (p *falliblePaginator[T]) Items() ([]T, error) {
+	return p.Slice(p.Offset(), p.Count())
+}
+
+func (p *falliblePaginator[T]) Prev() FalliblePaginator[T] {
+	return &falliblePaginator[T]{pages: p.page(p.currentPage - 1), slice: p.slice}
+}
+
+func (p *falliblePaginator[T]) Next() FalliblePaginator[T] {
+	return &falliblePaginator[T]{pages: p.page(p.currentPage + 1), slice: p.slice}
+}
+
+// bound limits offset and limit to the items there are.
+func (p pages) bound(offset, limit int64) (int64, int64) {
+	offset = min(max(offset, 0), p.total)
+	limit = max(min(limit, p.total-offset), 0)
+	return offset, limit
+}
+
+// page returns the given page of the same list.
+func (p pages) page(currentPage int64) pages {
+	return pages{currentPage: currentPage, perPage: p.perPage, total: p.total}
+}
+
+func (p pages) CurrentPage() int64 {
+	return p.currentPage
+}
+
+func (p pages) HasPrev() bool {
+	return p.currentPage > 1
+}
+
+func (p pages) HasNext() bool {
+	return p.currentPage < p.TotalPage()
 }
 
-func (p *paginator[T]) Offset() int64 {
+func (p pages) Offset() int64 {
 	return (p.currentPage - 1) * p.perPage
 }
 
-func (p *paginator[T]) Count() int64 {
+func (p pages) Count() int64 {
 	return min(p.perPage, p.total-p.Offset())
 }
 
-func (p *paginator[T]) Total() int64 {
+func (p pages) Total() int64 {
 	return p.total
 }
 
-func (p *paginator[T]) PerPage() int64 {
+func (p pages) PerPage() int64 {
 	return p.perPage
 }
 
-func (p *paginator[T]) TotalPage() int64 {
+func (p pages) TotalPage() int64 {
 	if p.total == 0 {
 		return 0
 	}
@@ -2,6 +2,7 @@ package util_test
 
 import (
 	"cake-scraper/pkg/util"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -73,3 +74,49 @@ func TestPaginatorSuite(t *testing.T) {
 		},
 	})
 }
+
+type FalliblePaginatorSuite struct {
+	suite.Suite
+}
+
+func (s *FalliblePaginatorSuite) TestItems() {
+	// Given
+	data := []int{1, 2, 3, 4, 5}
+	p := util.NewFalliblePaginator(func(offset, limit int64) ([]int, error) {
+		return data[offset : offset+limit], nil
+	}, 2, 3, int64(len(data)))
+
+	// When
+	items, err := p.Items()
+	prev, prevErr := p.Prev().Items()
+	past, pastErr := p.Next().Items()
+
+	// Then
+	s.Require().NoError(err)
+	s.Require().NoError(prevErr)
+	s.Require().NoError(pastErr)
+	s.Equal([]int{4, 5}, items)
+	s.Equal([]int{1, 2, 3}, prev)
+	s.Empty(past)
+	s.False(p.HasNext())
+}
+
+func (s *FalliblePaginatorSuite) TestItems_Error() {
+	// Given
+	want := errors.New("database is locked")
+	p := util.NewFalliblePaginator(func(offset, limit int64) ([]int, error) {
+		return nil, want
+	}, 1, 3, 10)
+
+	// When
+	items, err := p.Items()
+
+	// Then
+	s.ErrorIs(err, want)
+	s.Nil(items)
+	s.Equal(int64(4), p.TotalPage())
+}
+
+func TestFalliblePaginatorSuite(t *testing.T) {
+	suite.Run(t, new(FalliblePaginatorSuite))
+}
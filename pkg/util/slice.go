@@ -37,3 +37,15 @@ func Map[T, U any](data []T, mapper func(T) U) []U {
 	}
 	return mapped
 }
+
+// Chunk splits the given slice into consecutive chunks of at most size elements.
+func Chunk[T any](data []T, size int) [][]T {
+	chunks := make([][]T, 0, (len(data)+size-1)/size)
+	for size < len(data) {
+		data, chunks = data[size:], append(chunks, data[:size:size])
+	}
+	if len(data) > 0 {
+		chunks = append(chunks, data)
+	}
+	return chunks
+}
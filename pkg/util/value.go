@@ -0,0 +1,21 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MatchValue reports whether value equals want, or is among want if it is a
+// slice, as a column does a squirrel Eq condition. Values are compared by
+// their default formatting, so that e.g. an enum matches its number.
+func MatchValue(value, want any) bool {
+	if v := reflect.ValueOf(want); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if fmt.Sprint(value) == fmt.Sprint(v.Index(i).Interface()) {
+				return true
+			}
+		}
+		return false
+	}
+	return fmt.Sprint(value) == fmt.Sprint(want)
+}
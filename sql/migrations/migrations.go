@@ -0,0 +1,10 @@
+// Package migrations embeds the schema migrations of each dialect, in the
+// sqlite and postgres directories. Each version has a NNNN_name.up.sql file
+// applying it and a NNNN_name.down.sql file reverting it, and is the same
+// change in both dialects.
+package migrations
+
+import "embed"
+
+//go:embed sqlite/*.sql postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,67 @@
+-- Create jobs table
+CREATE TABLE jobs (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    company TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    employment_type INTEGER NOT NULL DEFAULT -1,
+    seniority INTEGER NOT NULL DEFAULT -1,
+    location TEXT NOT NULL DEFAULT '',
+    number_to_hire INTEGER NOT NULL DEFAULT 0,
+    experience TEXT NOT NULL DEFAULT '',
+    salary TEXT NOT NULL DEFAULT '',
+    remote INTEGER NOT NULL DEFAULT -1,
+    interview_process TEXT NOT NULL DEFAULT '',
+    job_description TEXT NOT NULL DEFAULT '',
+    requirements TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX uq_jobs_link ON jobs (link);
+
+-- Create tags table
+CREATE TABLE tags (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tag TEXT NOT NULL
+);
+CREATE UNIQUE INDEX uq_tags_tag ON tags (tag);
+
+-- Create jobs_tags table
+CREATE TABLE jobs_tags (
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, tag_id)
+);
+
+-- Create locations table
+CREATE TABLE locations (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    address TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    area TEXT NOT NULL DEFAULT '',
+    zip_code TEXT NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX uq_locations_address ON locations (address);
+
+-- Create jobs_locations table
+CREATE TABLE jobs_locations (
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    location_id BIGINT NOT NULL REFERENCES locations (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, location_id)
+);
+
+-- Create categories table
+CREATE TABLE categories (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    main TEXT NOT NULL,
+    sub TEXT NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX uq_categories_main_sub ON categories (main, sub);
+
+-- Create jobs_categories table
+CREATE TABLE jobs_categories (
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, category_id)
+);
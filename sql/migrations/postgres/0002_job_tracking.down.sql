@@ -0,0 +1,13 @@
+DROP TABLE jobs_listings;
+DROP TABLE job_revisions;
+DROP TABLE jobs_scrape_runs;
+DROP TABLE scrape_runs;
+DROP INDEX idx_jobs_closed_at;
+DROP INDEX idx_jobs_first_seen_at;
+DROP INDEX idx_jobs_source;
+ALTER TABLE jobs DROP COLUMN changed_at;
+ALTER TABLE jobs DROP COLUMN closed_at;
+ALTER TABLE jobs DROP COLUMN last_seen_at;
+ALTER TABLE jobs DROP COLUMN first_seen_at;
+ALTER TABLE jobs DROP COLUMN fetched_at;
+ALTER TABLE jobs DROP COLUMN source;
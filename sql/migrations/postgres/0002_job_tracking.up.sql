@@ -0,0 +1,59 @@
+-- Track where jobs come from and when they are seen, closed and changed
+ALTER TABLE jobs ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN fetched_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN closed_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN changed_at TIMESTAMP;
+UPDATE jobs SET first_seen_at = created_at, last_seen_at = updated_at, fetched_at = updated_at;
+-- Every job scraped before sources existed came from Cake
+UPDATE jobs SET source = 'cake' WHERE source = '';
+CREATE INDEX idx_jobs_source ON jobs (source);
+CREATE INDEX idx_jobs_first_seen_at ON jobs (first_seen_at);
+CREATE INDEX idx_jobs_closed_at ON jobs (closed_at);
+
+-- Create scrape_runs table
+CREATE TABLE scrape_runs (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'running',
+    sources TEXT NOT NULL DEFAULT '[]',
+    professions TEXT NOT NULL DEFAULT '[]',
+    pages_requested INTEGER NOT NULL DEFAULT 0,
+    pages_visited INTEGER NOT NULL DEFAULT 0,
+    jobs_new INTEGER NOT NULL DEFAULT 0,
+    jobs_updated INTEGER NOT NULL DEFAULT 0,
+    jobs_unchanged INTEGER NOT NULL DEFAULT 0,
+    jobs_skipped INTEGER NOT NULL DEFAULT 0,
+    jobs_closed INTEGER NOT NULL DEFAULT 0,
+    errors INTEGER NOT NULL DEFAULT 0,
+    error_messages TEXT NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- Create jobs_scrape_runs table
+CREATE TABLE jobs_scrape_runs (
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    run_id BIGINT NOT NULL REFERENCES scrape_runs (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, run_id)
+);
+CREATE INDEX idx_jobs_scrape_runs_run_id ON jobs_scrape_runs (run_id);
+
+-- Create job_revisions table
+CREATE TABLE job_revisions (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    run_id BIGINT REFERENCES scrape_runs (id) ON DELETE SET NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changes TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX idx_job_revisions_job_id ON job_revisions (job_id);
+
+-- Create jobs_listings table
+CREATE TABLE jobs_listings (
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    listing TEXT NOT NULL,
+    PRIMARY KEY (job_id, listing)
+);
+CREATE INDEX idx_jobs_listings_listing ON jobs_listings (listing);
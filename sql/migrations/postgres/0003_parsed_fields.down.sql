@@ -0,0 +1,8 @@
+DROP INDEX idx_jobs_experience_years;
+DROP INDEX idx_jobs_salary_annual;
+ALTER TABLE jobs DROP COLUMN salary_annual;
+ALTER TABLE jobs DROP COLUMN salary_period;
+ALTER TABLE jobs DROP COLUMN salary_currency;
+ALTER TABLE jobs DROP COLUMN salary_max;
+ALTER TABLE jobs DROP COLUMN salary_min;
+ALTER TABLE jobs DROP COLUMN experience_years;
@@ -0,0 +1,9 @@
+-- Store experience and salary parsed into numbers
+ALTER TABLE jobs ADD COLUMN experience_years INTEGER;
+ALTER TABLE jobs ADD COLUMN salary_min DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN salary_max DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN salary_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN salary_period INTEGER NOT NULL DEFAULT -1;
+ALTER TABLE jobs ADD COLUMN salary_annual DOUBLE PRECISION;
+CREATE INDEX idx_jobs_salary_annual ON jobs (salary_annual);
+CREATE INDEX idx_jobs_experience_years ON jobs (experience_years);
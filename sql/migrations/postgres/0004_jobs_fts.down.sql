@@ -0,0 +1,4 @@
+DROP TRIGGER trg_jobs_fts ON jobs;
+DROP FUNCTION trg_jobs_fts;
+DROP TABLE jobs_fts;
+DROP FUNCTION jobs_fts_document;
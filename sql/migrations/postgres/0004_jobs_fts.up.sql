@@ -0,0 +1,26 @@
+-- Create jobs_fts table, the weighted search document of each job kept in
+-- sync with jobs by a trigger
+CREATE TABLE jobs_fts (
+    job_id BIGINT PRIMARY KEY REFERENCES jobs (id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL
+);
+CREATE INDEX idx_jobs_fts_document ON jobs_fts USING GIN (document);
+CREATE FUNCTION jobs_fts_document(title TEXT, company TEXT, job_description TEXT, requirements TEXT)
+RETURNS TSVECTOR LANGUAGE SQL IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('simple', title), 'A')
+        || setweight(to_tsvector('simple', company), 'B')
+        || setweight(to_tsvector('simple', job_description), 'C')
+        || setweight(to_tsvector('simple', requirements), 'D')
+$$;
+CREATE FUNCTION trg_jobs_fts() RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO jobs_fts (job_id, document)
+    VALUES (new.id, jobs_fts_document(new.title, new.company, new.job_description, new.requirements))
+    ON CONFLICT (job_id) DO UPDATE SET document = EXCLUDED.document;
+    RETURN NULL;
+END
+$$;
+CREATE TRIGGER trg_jobs_fts AFTER INSERT OR UPDATE OF title, company, job_description, requirements ON jobs
+FOR EACH ROW EXECUTE FUNCTION trg_jobs_fts();
+INSERT INTO jobs_fts (job_id, document)
+SELECT id, jobs_fts_document(title, company, job_description, requirements) FROM jobs;
@@ -0,0 +1,2 @@
+DROP INDEX idx_tags_canonical_tag_id;
+ALTER TABLE tags DROP COLUMN canonical_tag_id;
@@ -0,0 +1,4 @@
+-- The canonical tag, by tags.id, this tag is a spelling of, or NULL if it is
+-- canonical
+ALTER TABLE tags ADD COLUMN canonical_tag_id BIGINT REFERENCES tags (id) ON DELETE SET NULL;
+CREATE INDEX idx_tags_canonical_tag_id ON tags (canonical_tag_id);
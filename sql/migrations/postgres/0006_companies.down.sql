@@ -0,0 +1,3 @@
+DROP INDEX idx_jobs_company_id;
+ALTER TABLE jobs DROP COLUMN company_id;
+DROP TABLE companies;
@@ -0,0 +1,30 @@
+-- Create companies table, keyed by the slug of the company on its source
+CREATE TABLE companies (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    slug TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    industry TEXT NOT NULL DEFAULT '',
+    size TEXT NOT NULL DEFAULT '',
+    founded_year INTEGER NOT NULL DEFAULT 0,
+    location TEXT NOT NULL DEFAULT '',
+    website TEXT NOT NULL DEFAULT '',
+    benefits TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    fetched_at TIMESTAMP
+);
+CREATE UNIQUE INDEX uq_companies_slug ON companies (slug);
+
+-- The company of the job, by companies.id, or NULL if it is unknown
+ALTER TABLE jobs ADD COLUMN company_id BIGINT REFERENCES companies (id) ON DELETE SET NULL;
+CREATE INDEX idx_jobs_company_id ON jobs (company_id);
+
+-- Cake links are https://www.cake.me/companies/{slug}/jobs/{job}
+INSERT INTO companies (slug, name)
+SELECT substring(link FROM '/companies/([^/]+)/jobs/') AS slug, MAX(company)
+FROM jobs
+WHERE source = 'cake' AND link ~ '/companies/[^/]+/jobs/'
+GROUP BY slug;
+UPDATE jobs SET company_id = c.id
+FROM companies AS c
+WHERE jobs.source = 'cake' AND c.slug = substring(jobs.link FROM '/companies/([^/]+)/jobs/');
@@ -0,0 +1,10 @@
+-- Create saved_searches table. conditions are the serialized job conditions
+-- of the search.
+CREATE TABLE saved_searches (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL,
+    conditions TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_viewed_at TIMESTAMP
+);
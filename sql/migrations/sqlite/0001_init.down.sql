@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS jobs_categories;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS jobs_locations;
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS jobs_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS jobs;
@@ -1,5 +1,3 @@
-PRAGMA foreign_keys = ON;
-
 -- Create jobs table
 CREATE TABLE IF NOT EXISTS jobs (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -0,0 +1,4 @@
+DROP TRIGGER trg_jobs_fts_delete;
+DROP TRIGGER trg_jobs_fts_update;
+DROP TRIGGER trg_jobs_fts_insert;
+DROP TABLE jobs_fts;
@@ -0,0 +1,22 @@
+-- Create jobs_fts table, kept in sync with jobs by triggers
+CREATE VIRTUAL TABLE jobs_fts USING fts5(
+    title,
+    company,
+    job_description,
+    requirements,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER trg_jobs_fts_insert AFTER INSERT ON jobs BEGIN
+    INSERT INTO jobs_fts (rowid, title, company, job_description, requirements)
+    VALUES (new.id, new.title, new.company, new.job_description, new.requirements);
+END;
+CREATE TRIGGER trg_jobs_fts_update AFTER UPDATE OF title, company, job_description, requirements ON jobs BEGIN
+    UPDATE jobs_fts
+    SET title = new.title, company = new.company, job_description = new.job_description, requirements = new.requirements
+    WHERE rowid = new.id;
+END;
+CREATE TRIGGER trg_jobs_fts_delete AFTER DELETE ON jobs BEGIN
+    DELETE FROM jobs_fts WHERE rowid = old.id;
+END;
+INSERT INTO jobs_fts (rowid, title, company, job_description, requirements)
+SELECT id, title, company, job_description, requirements FROM jobs;
@@ -0,0 +1,44 @@
+-- Create companies table, keyed by the slug of the company on its source
+CREATE TABLE companies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    industry TEXT NOT NULL DEFAULT '',
+    size TEXT NOT NULL DEFAULT '',
+    founded_year INTEGER NOT NULL DEFAULT 0,
+    location TEXT NOT NULL DEFAULT '',
+    website TEXT NOT NULL DEFAULT '',
+    benefits TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    fetched_at TEXT
+);
+CREATE UNIQUE INDEX uq_companies_slug ON companies (slug);
+
+-- The company of the job, by companies.id, or NULL if it is unknown. It has
+-- no foreign key so that it can be dropped again.
+ALTER TABLE jobs ADD COLUMN company_id INTEGER;
+CREATE INDEX idx_jobs_company_id ON jobs (company_id);
+
+-- Cake links are https://www.cake.me/companies/{slug}/jobs/{job}
+INSERT INTO companies (slug, name)
+SELECT slug, MAX(company)
+FROM (
+    SELECT substr(path, 1, instr(path, '/jobs/') - 1) AS slug, company
+    FROM (
+        SELECT substr(link, instr(link, '/companies/') + length('/companies/')) AS path, company
+        FROM jobs
+        WHERE source = 'cake' AND link LIKE '%/companies/%/jobs/%'
+    )
+)
+GROUP BY slug;
+UPDATE jobs SET company_id = (
+    SELECT c.id
+    FROM companies AS c
+    WHERE c.slug = substr(
+        substr(jobs.link, instr(jobs.link, '/companies/') + length('/companies/')),
+        1,
+        instr(substr(jobs.link, instr(jobs.link, '/companies/') + length('/companies/')), '/jobs/') - 1
+    )
+)
+WHERE source = 'cake' AND link LIKE '%/companies/%/jobs/%';